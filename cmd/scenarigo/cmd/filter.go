@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zoncoen/scenarigo"
+)
+
+var (
+	tags        []string
+	excludeTags []string
+	runPattern  string
+	rerunFailed string
+	shard       string
+	shardReport string
+)
+
+func init() {
+	for _, c := range []*cobra.Command{runCmd, listCmd, lintCmd} {
+		c.Flags().StringSliceVar(&tags, "tags", nil, "run only scenarios which have any of the specified tags")
+		c.Flags().StringSliceVar(&excludeTags, "exclude-tags", nil, "skip scenarios which have any of the specified tags")
+		c.Flags().StringVar(&runPattern, "run", "", "run only scenarios whose file path, title, or step titles match the regular expression")
+	}
+	for _, c := range []*cobra.Command{runCmd, listCmd} {
+		c.Flags().StringVar(&rerunFailed, "rerun-failed", "", "run only failed scenarios in the JSON test report")
+		c.Flags().StringVar(&shard, "shard", "", "run only the i-th shard of scenario files divided into n shards (i/n)")
+		c.Flags().StringVar(&shardReport, "shard-report", "", "balance the shards by the durations in the JSON test report")
+	}
+}
+
+func filterOptions() []func(*scenarigo.Runner) error {
+	return []func(*scenarigo.Runner) error{
+		scenarigo.WithTags(tags...),
+		scenarigo.WithExcludeTags(excludeTags...),
+		scenarigo.WithRunPattern(runPattern),
+		scenarigo.WithRerunFailed(rerunFailed),
+		func(r *scenarigo.Runner) error {
+			if shard == "" {
+				return nil
+			}
+			index, total, err := parseShard(shard)
+			if err != nil {
+				return err
+			}
+			return scenarigo.WithShard(index, total)(r)
+		},
+		scenarigo.WithShardReport(shardReport),
+	}
+}
+
+// parseShard parses the shard like "1/8".
+func parseShard(s string) (int, int, error) {
+	i := strings.Index(s, "/")
+	if i < 0 {
+		return 0, 0, fmt.Errorf("invalid shard %q: must be i/n", s)
+	}
+	index, err := strconv.Atoi(s[:i])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid shard %q: %w", s, err)
+	}
+	total, err := strconv.Atoi(s[i+1:])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid shard %q: %w", s, err)
+	}
+	return index, total, nil
+}
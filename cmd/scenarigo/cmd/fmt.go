@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/zoncoen/scenarigo"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+// ErrFormatCheckFailed is the error returned when the check mode found unformatted files.
+var ErrFormatCheckFailed = errors.New("some files are not formatted")
+
+var fmtCheck bool
+
+var fmtCmd = &cobra.Command{
+	Use:           "fmt",
+	Short:         "format the test scenario files",
+	Long:          "Rewrites the test scenario files into the canonical form.",
+	RunE:          format,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+func init() {
+	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false, "only check the files are formatted and list unformatted files")
+	rootCmd.AddCommand(fmtCmd)
+}
+
+func format(cmd *cobra.Command, args []string) error {
+	return formatWithConfig(cmd, args, configFile)
+}
+
+func formatWithConfig(cmd *cobra.Command, args []string, configPath string) error {
+	opts := []func(*scenarigo.Runner) error{}
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg != nil {
+		if len(args) > 0 {
+			cfg.Scenarios = nil
+		}
+		opts = append(opts, scenarigo.WithConfig(cfg))
+	}
+	if len(args) > 0 {
+		opts = append(opts, scenarigo.WithScenarios(args...))
+	}
+	r, err := scenarigo.NewRunner(opts...)
+	if err != nil {
+		return err
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	var unformatted bool
+	for _, file := range r.ScenarioFiles() {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		formatted, err := schema.Format(b)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if bytes.Equal(b, formatted) {
+			continue
+		}
+		unformatted = true
+		rel, err := filepath.Rel(wd, file)
+		if err != nil {
+			return fmt.Errorf("failed to get releative path: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), rel)
+		if fmtCheck {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("failed to get file info: %w", err)
+		}
+		if err := os.WriteFile(file, formatted, info.Mode()); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file, err)
+		}
+	}
+	if fmtCheck && unformatted {
+		return ErrFormatCheckFailed
+	}
+	return nil
+}
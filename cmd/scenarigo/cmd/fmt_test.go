@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+)
+
+func TestFormat(t *testing.T) {
+	unformatted := `steps:
+- protocol: http
+  title: get
+title: scenario
+`
+	formatted := `title: scenario
+steps:
+- title: get
+  protocol: http
+`
+	tests := map[string]struct {
+		src    string
+		check  bool
+		expect error
+		output bool
+		result string
+	}{
+		"format": {
+			src:    unformatted,
+			output: true,
+			result: formatted,
+		},
+		"already formatted": {
+			src:    formatted,
+			result: formatted,
+		},
+		"check": {
+			src:    unformatted,
+			check:  true,
+			expect: ErrFormatCheckFailed,
+			output: true,
+			result: unformatted,
+		},
+		"check formatted": {
+			src:    formatted,
+			check:  true,
+			result: formatted,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			fmtCheck = test.check
+			defer func() { fmtCheck = false }()
+			file := filepath.Join(t.TempDir(), "scenario.yaml")
+			if err := os.WriteFile(file, []byte(test.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cmd := &cobra.Command{}
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			if err := formatWithConfig(cmd, []string{file}, ""); err != test.expect {
+				t.Fatalf("expected error %v but got %v", test.expect, err)
+			}
+			if got := buf.Len() > 0; got != test.output {
+				t.Errorf("unexpected output: %q", buf.String())
+			}
+			b, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.result, string(b)); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/zoncoen/scenarigo"
+)
+
+// ErrLintFailed is the error returned when the lint found any problems.
+var ErrLintFailed = errors.New("lint failed")
+
+var lintCmd = &cobra.Command{
+	Use:           "lint",
+	Short:         "check the test scenario files",
+	Long:          "Checks the test scenario files statically without running them.",
+	RunE:          lint,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+}
+
+func lint(cmd *cobra.Command, args []string) error {
+	return lintWithConfig(cmd, args, configFile)
+}
+
+func lintWithConfig(cmd *cobra.Command, args []string, configPath string) error {
+	opts := []func(*scenarigo.Runner) error{}
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg != nil {
+		if len(args) > 0 {
+			cfg.Scenarios = nil
+		}
+		opts = append(opts, scenarigo.WithConfig(cfg))
+	}
+	if len(args) > 0 {
+		opts = append(opts, scenarigo.WithScenarios(args...))
+	}
+	opts = append(opts, filterOptions()...)
+	varsOpts, err := varsOptions()
+	if err != nil {
+		return err
+	}
+	opts = append(opts, varsOpts...)
+	r, err := scenarigo.NewRunner(opts...)
+	if err != nil {
+		return err
+	}
+
+	errs := r.Lint()
+	for _, err := range errs {
+		fmt.Fprintln(cmd.OutOrStdout(), err)
+	}
+	if len(errs) > 0 {
+		return ErrLintFailed
+	}
+	return nil
+}
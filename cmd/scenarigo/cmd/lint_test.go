@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestLint(t *testing.T) {
+	tests := map[string]struct {
+		args      []string
+		config    string
+		vars      []string
+		expect    error
+		output    []string
+		notOutput []string
+	}{
+		"valid": {
+			args: []string{"testdata/scenarios/pass.yaml"},
+		},
+		"invalid": {
+			args:   []string{"testdata/lint/invalid.yaml"},
+			expect: ErrLintFailed,
+			output: []string{
+				`invalid template: failed to parse "{{env.TEST_ADDR"`,
+				"plugin not-found.so not found",
+				`invalid wait: time: unknown unit " second" in duration "1 second"`,
+				"included file not-found.yaml not found",
+				`variable "baseURL" is not defined`,
+				"request can't be specified with wait",
+			},
+		},
+		"defined by flag": {
+			args:      []string{"testdata/lint/invalid.yaml"},
+			vars:      []string{"baseURL=http://localhost"},
+			expect:    ErrLintFailed,
+			notOutput: []string{`variable "baseURL" is not defined`},
+		},
+		"defined by inputs": {
+			args: []string{"testdata/lint/include"},
+		},
+		"defined by inputs and linted alone": {
+			args: []string{"testdata/lint/include/login.yaml"},
+		},
+		"defined by caller and linted alone": {
+			args: []string{"testdata/lint/include/me.yaml"},
+		},
+		"outputs out of bind": {
+			args:   []string{"testdata/lint/outputs"},
+			expect: ErrLintFailed,
+			output: []string{`output "token" is available only in bind of the include step`},
+		},
+		"invalid step library": {
+			config: "testdata/lint/library/scenarigo.yaml",
+			expect: ErrLintFailed,
+			output: []string{
+				"steps/auth.yaml:",
+				`>  6 |     url: "{{env.TEST_ADDR/login"`,
+				`invalid template: failed to parse "{{env.TEST_ADDR/login"`,
+			},
+		},
+		"defined by setup": {
+			config: "testdata/lint/setup/scenarigo.yaml",
+		},
+		"overridden setup variable": {
+			config: "testdata/lint/override/scenarigo.yaml",
+			expect: ErrLintFailed,
+			output: []string{`variable "token" is bound by the setup scenarios and can't be overridden`},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			varsFlags = test.vars
+			defer func() { varsFlags = nil }()
+			cmd := &cobra.Command{}
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			if err := lintWithConfig(cmd, test.args, test.config); err != test.expect {
+				t.Fatalf("expected error %v but got %v", test.expect, err)
+			}
+			got := buf.String()
+			if test.expect == nil && got != "" {
+				t.Fatalf("unexpected output: %s", got)
+			}
+			for _, o := range test.output {
+				if !strings.Contains(got, o) {
+					t.Errorf("output does not contain %q:\n%s", o, got)
+				}
+			}
+			for _, o := range test.notOutput {
+				if strings.Contains(got, o) {
+					t.Errorf("output contains %q:\n%s", o, got)
+				}
+			}
+		})
+	}
+}
@@ -52,6 +52,7 @@ func listWithConfig(cmd *cobra.Command, args []string, configPath string) error
 	for _, arg := range args {
 		opts = append(opts, scenarigo.WithScenarios(arg))
 	}
+	opts = append(opts, filterOptions()...)
 	r, err := scenarigo.NewRunner(opts...)
 	if err != nil {
 		return err
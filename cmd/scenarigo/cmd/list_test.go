@@ -59,4 +59,83 @@ testdata/scenarios/pass.yaml
 			t.Errorf("stdout differs:\n%s", dmp.DiffPrettyText(diffs))
 		}
 	})
+	t.Run("filter by tags", func(t *testing.T) {
+		tags = []string{"smoke"}
+		defer func() { tags = nil }()
+		cmd := &cobra.Command{}
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		if err := listWithConfig(cmd, []string{}, "./testdata/scenarigo.yaml"); err != nil {
+			t.Fatal(err)
+		}
+		expect := strings.TrimPrefix(`
+testdata/scenarios/pass.yaml
+`, "\n")
+		if got := buf.String(); got != expect {
+			dmp := diffmatchpatch.New()
+			diffs := dmp.DiffMain(expect, got, false)
+			t.Errorf("stdout differs:\n%s", dmp.DiffPrettyText(diffs))
+		}
+	})
+	t.Run("filter by run pattern", func(t *testing.T) {
+		runPattern = "fail"
+		defer func() { runPattern = "" }()
+		cmd := &cobra.Command{}
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		if err := listWithConfig(cmd, []string{}, "./testdata/scenarigo.yaml"); err != nil {
+			t.Fatal(err)
+		}
+		expect := strings.TrimPrefix(`
+testdata/scenarios/fail.yaml
+`, "\n")
+		if got := buf.String(); got != expect {
+			dmp := diffmatchpatch.New()
+			diffs := dmp.DiffMain(expect, got, false)
+			t.Errorf("stdout differs:\n%s", dmp.DiffPrettyText(diffs))
+		}
+	})
+	t.Run("rerun failed", func(t *testing.T) {
+		rerunFailed = "testdata/report.json"
+		defer func() { rerunFailed = "" }()
+		cmd := &cobra.Command{}
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		if err := listWithConfig(cmd, []string{}, "./testdata/scenarigo.yaml"); err != nil {
+			t.Fatal(err)
+		}
+		expect := strings.TrimPrefix(`
+testdata/scenarios/fail.yaml
+`, "\n")
+		if got := buf.String(); got != expect {
+			dmp := diffmatchpatch.New()
+			diffs := dmp.DiffMain(expect, got, false)
+			t.Errorf("stdout differs:\n%s", dmp.DiffPrettyText(diffs))
+		}
+	})
+	t.Run("shard", func(t *testing.T) {
+		shard = "2/2"
+		defer func() { shard = "" }()
+		cmd := &cobra.Command{}
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		if err := listWithConfig(cmd, []string{}, "./testdata/scenarigo.yaml"); err != nil {
+			t.Fatal(err)
+		}
+		expect := strings.TrimPrefix(`
+testdata/scenarios/pass.yaml
+`, "\n")
+		if got := buf.String(); got != expect {
+			dmp := diffmatchpatch.New()
+			diffs := dmp.DiffMain(expect, got, false)
+			t.Errorf("stdout differs:\n%s", dmp.DiffPrettyText(diffs))
+		}
+	})
+	t.Run("invalid shard", func(t *testing.T) {
+		shard = "1-2"
+		defer func() { shard = "" }()
+		if err := listWithConfig(&cobra.Command{}, []string{}, "./testdata/scenarigo.yaml"); err == nil {
+			t.Fatal("expected error but no error")
+		}
+	})
 }
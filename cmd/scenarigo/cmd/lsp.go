@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/zoncoen/scenarigo"
+	"github.com/zoncoen/scenarigo/internal/lsp"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "run the language server",
+	Long: `Runs the language server for scenario files on stdio.
+It provides diagnostics by the lint, completion of variables and assertion functions, go-to-definition of included files, and hover of templates.`,
+	Args:          cobra.ExactArgs(0),
+	RunE:          runLSP,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+}
+
+func runLSP(cmd *cobra.Command, args []string) error {
+	opts := []func(*scenarigo.Runner) error{}
+	cfg, err := loadConfig(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg != nil {
+		// the language server lints opened documents instead of the scenario files
+		cfg.Scenarios = nil
+		opts = append(opts, scenarigo.WithConfig(cfg))
+	}
+	r, err := scenarigo.NewRunner(opts...)
+	if err != nil {
+		return err
+	}
+	return lsp.NewServer(r).Serve(os.Stdin, os.Stdout)
+}
@@ -0,0 +1,362 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/zoncoen/scenarigo"
+)
+
+var pluginCmd = &cobra.Command{
+	Use:           "plugin",
+	Short:         "manage the plugins",
+	Long:          "Manages the plugins.",
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+var pluginBuildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "build the plugins",
+	Long: strings.Trim(`
+Builds the plugins referenced by the test scenarios and the configuration from the sources declared in "pluginSources".
+The plugins are built with the same Go version, module versions, and build flags as scenarigo to be loaded without version errors.
+The built plugins are cached by the hash of the sources.
+`, "\n"),
+	Args:          cobra.ExactArgs(0),
+	RunE:          buildPlugins,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginBuildCmd)
+	rootCmd.AddCommand(pluginCmd)
+}
+
+func buildPlugins(cmd *cobra.Command, args []string) error {
+	return buildPluginsWithConfig(cmd, configFile)
+}
+
+func buildPluginsWithConfig(cmd *cobra.Command, configPath string) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg == nil {
+		return errors.New("config file not found")
+	}
+	r, err := scenarigo.NewRunner(scenarigo.WithConfig(cfg))
+	if err != nil {
+		return err
+	}
+	paths, err := r.PluginPaths()
+	if err != nil {
+		return err
+	}
+	srcs := map[string]string{}
+	for out, src := range cfg.PluginSources {
+		srcs[filepath.Clean(out)] = filepath.Join(cfg.Root, src)
+	}
+	plugins := goPlugins(paths, srcs)
+	if len(plugins) == 0 {
+		return nil
+	}
+
+	if err := checkGoVersion(); err != nil {
+		return err
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return errors.New("failed to read build information of scenarigo")
+	}
+	mods, warnings := scenarigoModules(info)
+	for _, w := range warnings {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s\n", w)
+	}
+	flags := buildFlags(info)
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return fmt.Errorf("failed to get cache directory: %w", err)
+	}
+	cacheDir = filepath.Join(cacheDir, appName, "plugins")
+
+	for _, p := range plugins {
+		src, ok := srcs[p]
+		if !ok {
+			return fmt.Errorf("source of plugin %s not found: add it to pluginSources", p)
+		}
+		out := filepath.Join(cfg.Root, cfg.PluginDirectory, p)
+		cached, err := buildPlugin(src, out, mods, flags, cacheDir)
+		if err != nil {
+			return fmt.Errorf("failed to build plugin %s: %w", p, err)
+		}
+		if cached {
+			fmt.Fprintf(cmd.OutOrStdout(), "build plugin %s (cached)\n", p)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "build plugin %s\n", p)
+		}
+	}
+	return nil
+}
+
+// goPlugins returns the Go plugins in paths.
+// The plugins with the ".so" extension or the sources in srcs are Go plugins.
+// The other out-of-process plugins are ignored because they are not built by scenarigo.
+func goPlugins(paths []string, srcs map[string]string) []string {
+	plugins := []string{}
+	for _, p := range paths {
+		if _, ok := srcs[p]; ok || filepath.Ext(p) == ".so" {
+			plugins = append(plugins, p)
+		}
+	}
+	return plugins
+}
+
+// checkGoVersion checks the go command is the same version as the one which built scenarigo.
+func checkGoVersion() error {
+	b, err := exec.Command("go", "env", "GOVERSION").Output()
+	if err != nil {
+		return fmt.Errorf("failed to get Go version: %w", err)
+	}
+	if v := strings.TrimSpace(string(b)); v != runtime.Version() {
+		return fmt.Errorf("scenarigo was built with %s but the go command is %s", runtime.Version(), v)
+	}
+	return nil
+}
+
+// scenarigoModules returns the modules which scenarigo was built with.
+// The modules which can't be reproduced like the local directory replacements are not pinned,
+// so it also returns the warnings of them because the plugins may fail to be loaded.
+func scenarigoModules(info *debug.BuildInfo) ([]*debug.Module, []string) {
+	mods := []*debug.Module{}
+	var warnings []string
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		mods = append(mods, &info.Main)
+	} else {
+		warnings = append(warnings, fmt.Sprintf("%s is not pinned because it was built from the local source: the version required by the plugin is used", info.Main.Path))
+	}
+	for _, m := range info.Deps {
+		if m.Replace != nil && m.Replace.Version == "" {
+			warnings = append(warnings, fmt.Sprintf("%s is not pinned because it is replaced by the local directory %s: the version required by the plugin is used", m.Path, m.Replace.Path))
+			continue
+		}
+		mods = append(mods, m)
+	}
+	return mods, warnings
+}
+
+// buildFlags returns the flags of "go build" which scenarigo was built with.
+// The plugins must be built with the same flags as scenarigo to be loaded.
+func buildFlags(info *debug.BuildInfo) []string {
+	var flags []string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "-trimpath":
+			if s.Value == "true" {
+				flags = append(flags, "-trimpath")
+			}
+		case "-tags":
+			if s.Value != "" {
+				flags = append(flags, "-tags="+s.Value)
+			}
+		}
+	}
+	return flags
+}
+
+// buildPlugin builds the plugin of src into out and reports whether the cached plugin was used.
+// The go.mod of src is not modified because the rewritten one is used by the -modfile flag.
+func buildPlugin(src, out string, mods []*debug.Module, flags []string, cacheDir string) (bool, error) {
+	tmp, err := os.MkdirTemp("", "scenarigo-plugin-")
+	if err != nil {
+		return false, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	modfile := filepath.Join(tmp, "go.mod")
+	if err := copyFile(filepath.Join(src, "go.mod"), modfile); err != nil {
+		return false, err
+	}
+	if err := copyFile(filepath.Join(src, "go.sum"), filepath.Join(tmp, "go.sum")); err != nil && !os.IsNotExist(errors.Cause(err)) {
+		return false, err
+	}
+
+	args := []string{"mod", "edit"}
+	for _, m := range mods {
+		args = append(args, fmt.Sprintf("-require=%s@%s", m.Path, m.Version))
+		if m.Replace != nil {
+			args = append(args, fmt.Sprintf("-replace=%s=%s@%s", m.Path, m.Replace.Path, m.Replace.Version))
+		}
+	}
+	args = append(args, modfile)
+	if err := goCommand(src, args...); err != nil {
+		return false, fmt.Errorf("failed to rewrite go.mod: %w", err)
+	}
+	if err := checkModules(src, modfile, mods); err != nil {
+		return false, err
+	}
+
+	hash, err := hashPlugin(src, tmp, flags)
+	if err != nil {
+		return false, err
+	}
+	cache := filepath.Join(cacheDir, hash+".so")
+	cached := true
+	if _, err := os.Stat(cache); err != nil {
+		if !os.IsNotExist(err) {
+			return false, err
+		}
+		cached = false
+		if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+			return false, fmt.Errorf("failed to create cache directory: %w", err)
+		}
+		// build into the temporary file not to leave the broken cache
+		tmpOut := cache + ".tmp"
+		args = append([]string{"build", "-buildmode=plugin", "-mod=mod", "-modfile=" + modfile}, flags...)
+		if err := goCommand(src, append(args, "-o", tmpOut, ".")...); err != nil {
+			return false, err
+		}
+		if err := os.Rename(tmpOut, cache); err != nil {
+			return false, fmt.Errorf("failed to save cache: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		return false, fmt.Errorf("failed to create plugin directory: %w", err)
+	}
+	if err := copyFile(cache, out); err != nil {
+		return false, err
+	}
+	return cached, nil
+}
+
+// checkModules checks the plugin selects the same versions as scenarigo.
+// The versions may differ if the plugin requires newer versions of the modules which scenarigo depends on.
+func checkModules(src, modfile string, mods []*debug.Module) error {
+	expect := map[string]string{}
+	for _, m := range mods {
+		if m.Replace != nil {
+			expect[m.Path] = m.Replace.Version
+		} else {
+			expect[m.Path] = m.Version
+		}
+	}
+	cmd := exec.Command("go", "list", "-m", "-json", "-mod=mod", "-modfile="+modfile, "all")
+	cmd.Dir = src
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	b, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to list modules: %w\n%s", err, stderr.String())
+	}
+	var mismatches []string
+	d := json.NewDecoder(bytes.NewReader(b))
+	for d.More() {
+		var m struct {
+			Path    string
+			Version string
+			Replace *struct {
+				Version string
+			}
+		}
+		if err := d.Decode(&m); err != nil {
+			return fmt.Errorf("failed to list modules: %w", err)
+		}
+		v, ok := expect[m.Path]
+		if !ok {
+			continue
+		}
+		got := m.Version
+		if m.Replace != nil {
+			got = m.Replace.Version
+		}
+		if got != v {
+			mismatches = append(mismatches, fmt.Sprintf("%s: plugin requires %s but scenarigo was built with %s", m.Path, got, v))
+		}
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("module versions mismatch:\n%s", strings.Join(mismatches, "\n"))
+	}
+	return nil
+}
+
+// hashPlugin returns the hash of the plugin sources in src, the rewritten go.mod and go.sum in modDir, and the build flags.
+func hashPlugin(src, modDir string, flags []string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s/%s %s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH, strings.Join(flags, " "))
+	for _, name := range []string{"go.mod", "go.sum"} {
+		if err := hashFile(h, name, filepath.Join(modDir, name)); err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if rel == "go.mod" || rel == "go.sum" {
+			return nil
+		}
+		return hashFile(h, filepath.ToSlash(rel), path)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read plugin sources: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(w io.Writer, name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fmt.Fprintf(w, "%s\x00", name)
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func goCommand(dir string, args ...string) error {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("go %s: %w\n%s", args[0], err, out)
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	b, err := os.ReadFile(src)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", src)
+	}
+	if err := os.WriteFile(dst, b, 0o644); err != nil {
+		return errors.Wrapf(err, "failed to write %s", dst)
+	}
+	return nil
+}
@@ -0,0 +1,338 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+)
+
+func TestBuildPlugins(t *testing.T) {
+	root, err := filepath.Abs("../../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gosum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gomod := `module example.com/plugin
+
+go 1.16
+
+require (
+	github.com/fatih/color v1.10.0
+	github.com/zoncoen/scenarigo v0.0.0
+)
+
+replace github.com/zoncoen/scenarigo => ` + root + `
+`
+	src := `package main
+
+import (
+	"github.com/zoncoen/scenarigo/plugin"
+)
+
+func Greet(ctx *plugin.Context) string {
+	return "Hello!"
+}
+`
+
+	tests := map[string]struct {
+		config    string
+		files     map[string]string
+		expect    []string
+		expectErr bool
+	}{
+		"build": {
+			config: `
+schemaVersion: config/v1
+scenarios:
+- scenario.yaml
+pluginDirectory: gen
+pluginSources:
+  greet.so: src
+`,
+			files: map[string]string{
+				"scenario.yaml": `
+title: greet
+plugins:
+  greet: greet.so
+`,
+			},
+			expect: []string{"greet.so"},
+		},
+		"step library": {
+			config: `
+schemaVersion: config/v1
+scenarios:
+- scenario.yaml
+pluginDirectory: gen
+pluginSources:
+  greet.so: src
+stepLibraries:
+- steps
+`,
+			files: map[string]string{
+				"scenario.yaml": `
+title: greet
+plugins:
+  greet: greet.so
+steps:
+- title: login
+  uses: auth.login
+`,
+				"steps/auth.yaml": `
+login:
+  title: login
+  protocol: http
+  request:
+    method: POST
+    url: "http://localhost/login"
+`,
+			},
+			expect: []string{"greet.so"},
+		},
+		"setup and teardown": {
+			config: `
+schemaVersion: config/v1
+scenarios:
+- scenario.yaml
+setup:
+- setup.yaml
+teardown:
+- teardown.yaml
+pluginDirectory: gen
+pluginSources:
+  greet.so: src
+  bye.so: src
+`,
+			files: map[string]string{
+				"scenario.yaml": `
+title: greet
+`,
+				"setup.yaml": `
+title: setup
+plugins:
+  greet: greet.so
+`,
+				"teardown.yaml": `
+title: teardown
+plugins:
+  bye: bye.so
+`,
+			},
+			expect: []string{"bye.so", "greet.so"},
+		},
+		"no plugins": {
+			config: `
+schemaVersion: config/v1
+scenarios:
+- scenario.yaml
+pluginSources:
+  greet.so: src
+`,
+			files: map[string]string{
+				"scenario.yaml": `
+title: greet
+`,
+			},
+		},
+		"source not found": {
+			config: `
+schemaVersion: config/v1
+scenarios:
+- scenario.yaml
+pluginDirectory: gen
+`,
+			files: map[string]string{
+				"scenario.yaml": `
+title: greet
+plugins:
+  greet: greet.so
+`,
+			},
+			expectErr: true,
+		},
+	}
+	// share the cache not to build the same plugin for every test case
+	cacheHome := os.Getenv("XDG_CACHE_HOME")
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Setenv("XDG_CACHE_HOME", cacheHome)
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := map[string]string{
+				"scenarigo.yaml": test.config,
+				"src/go.mod":     gomod,
+				"src/go.sum":     string(gosum),
+				"src/main.go":    src,
+			}
+			for name, content := range test.files {
+				files[name] = content
+			}
+			for name, content := range files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cmd := &cobra.Command{}
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			err := buildPluginsWithConfig(cmd, filepath.Join(dir, "scenarigo.yaml"))
+			if test.expectErr {
+				if err == nil {
+					t.Fatal("no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to build plugins: %s", err)
+			}
+			// the plugins may be cached by the other test cases
+			var got []string
+			for _, l := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+				if l == "" {
+					continue
+				}
+				got = append(got, strings.TrimSuffix(strings.TrimPrefix(l, "build plugin "), " (cached)"))
+			}
+			if diff := cmp.Diff(test.expect, got); diff != "" {
+				t.Errorf("built plugins differ (-want +got):\n%s", diff)
+			}
+			for _, p := range test.expect {
+				if _, err := os.Stat(filepath.Join(dir, "gen", p)); err != nil {
+					t.Fatalf("plugin not found: %s", err)
+				}
+			}
+			b, err := os.ReadFile(filepath.Join(dir, "src", "go.mod"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(gomod, string(b)); diff != "" {
+				t.Errorf("go.mod is modified (-want +got):\n%s", diff)
+			}
+			if len(test.expect) == 0 {
+				return
+			}
+
+			// use the cache
+			buf.Reset()
+			if err := buildPluginsWithConfig(cmd, filepath.Join(dir, "scenarigo.yaml")); err != nil {
+				t.Fatalf("failed to build plugins: %s", err)
+			}
+			for _, l := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+				if !strings.HasSuffix(l, " (cached)") {
+					t.Errorf("not cached: %s", l)
+				}
+			}
+		})
+	}
+}
+
+func TestScenarigoModules(t *testing.T) {
+	dep := &debug.Module{Path: "github.com/fatih/color", Version: "v1.10.0"}
+	local := &debug.Module{
+		Path:    "github.com/zoncoen/query-go",
+		Version: "v1.1.0",
+		Replace: &debug.Module{Path: "../query-go"},
+	}
+	tests := map[string]struct {
+		info           *debug.BuildInfo
+		expect         []*debug.Module
+		expectWarnings []string
+	}{
+		"release": {
+			info: &debug.BuildInfo{
+				Main: debug.Module{Path: "github.com/zoncoen/scenarigo", Version: "v0.9.0"},
+				Deps: []*debug.Module{dep},
+			},
+			expect: []*debug.Module{
+				{Path: "github.com/zoncoen/scenarigo", Version: "v0.9.0"},
+				dep,
+			},
+		},
+		"devel": {
+			info: &debug.BuildInfo{
+				Main: debug.Module{Path: "github.com/zoncoen/scenarigo", Version: "(devel)"},
+				Deps: []*debug.Module{dep},
+			},
+			expect: []*debug.Module{dep},
+			expectWarnings: []string{
+				"github.com/zoncoen/scenarigo is not pinned because it was built from the local source: the version required by the plugin is used",
+			},
+		},
+		"local replacement": {
+			info: &debug.BuildInfo{
+				Main: debug.Module{Path: "github.com/zoncoen/scenarigo", Version: "v0.9.0"},
+				Deps: []*debug.Module{dep, local},
+			},
+			expect: []*debug.Module{
+				{Path: "github.com/zoncoen/scenarigo", Version: "v0.9.0"},
+				dep,
+			},
+			expectWarnings: []string{
+				"github.com/zoncoen/query-go is not pinned because it is replaced by the local directory ../query-go: the version required by the plugin is used",
+			},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			mods, warnings := scenarigoModules(test.info)
+			if diff := cmp.Diff(test.expect, mods); diff != "" {
+				t.Errorf("modules differ (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.expectWarnings, warnings); diff != "" {
+				t.Errorf("warnings differ (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBuildFlags(t *testing.T) {
+	tests := map[string]struct {
+		settings []debug.BuildSetting
+		expect   []string
+	}{
+		"no flags": {
+			settings: []debug.BuildSetting{
+				{Key: "-compiler", Value: "gc"},
+				{Key: "CGO_ENABLED", Value: "1"},
+			},
+		},
+		"trimpath and tags": {
+			settings: []debug.BuildSetting{
+				{Key: "-compiler", Value: "gc"},
+				{Key: "-tags", Value: "netgo,osusergo"},
+				{Key: "-trimpath", Value: "true"},
+			},
+			expect: []string{"-tags=netgo,osusergo", "-trimpath"},
+		},
+		"trimpath disabled": {
+			settings: []debug.BuildSetting{
+				{Key: "-trimpath", Value: "false"},
+			},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			got := buildFlags(&debug.BuildInfo{Settings: test.settings})
+			if diff := cmp.Diff(test.expect, got); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	sub "github.com/zoncoen/scenarigo/cmd/scenarigo/cmd/report"
+)
+
+var reportCmd = &cobra.Command{
+	Use:           "report",
+	Short:         "manage test reports",
+	Long:          "Manages test reports.",
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+func init() {
+	for _, c := range sub.Commands() {
+		reportCmd.AddCommand(c)
+	}
+	rootCmd.AddCommand(reportCmd)
+}
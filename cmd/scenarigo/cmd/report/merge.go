@@ -0,0 +1,93 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/zoncoen/scenarigo/reporter"
+)
+
+var (
+	jsonOutput  string
+	junitOutput string
+)
+
+var mergeCmd = &cobra.Command{
+	Use:           "merge [JSON reports]",
+	Short:         "merge JSON test reports",
+	Long:          "Merges JSON test reports like the reports of shards into one report. It prints the merged JSON report if no output files are specified.",
+	Args:          cobra.MinimumNArgs(1),
+	RunE:          mergeRun,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+func init() {
+	mergeCmd.Flags().StringVar(&jsonOutput, "json", "", "write the merged report to the file in JSON")
+	mergeCmd.Flags().StringVar(&junitOutput, "junit", "", "write the merged report to the file in JUnit XML format")
+}
+
+func mergeRun(cmd *cobra.Command, args []string) error {
+	reports := make([]*reporter.TestReport, len(args))
+	for i, path := range args {
+		report, err := readReport(path)
+		if err != nil {
+			return err
+		}
+		reports[i] = report
+	}
+	report := reporter.MergeTestReports(reports...)
+
+	if jsonOutput == "" && junitOutput == "" {
+		return writeJSON(cmd.OutOrStdout(), report)
+	}
+	if jsonOutput != "" {
+		if err := writeFile(jsonOutput, report, writeJSON); err != nil {
+			return fmt.Errorf("failed to write JSON test report: %w", err)
+		}
+	}
+	if junitOutput != "" {
+		if err := writeFile(junitOutput, report, writeJUnit); err != nil {
+			return fmt.Errorf("failed to write JUnit test report: %w", err)
+		}
+	}
+	return nil
+}
+
+func readReport(path string) (*reporter.TestReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test report: %w", err)
+	}
+	defer f.Close()
+	var report reporter.TestReport
+	if err := json.NewDecoder(f).Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to read test report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+func writeFile(path string, report *reporter.TestReport, write func(io.Writer, *reporter.TestReport) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return write(f, report)
+}
+
+func writeJSON(w io.Writer, report *reporter.TestReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+func writeJUnit(w io.Writer, report *reporter.TestReport) error {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(report)
+}
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/zoncoen/scenarigo/reporter"
+)
+
+func TestMergeRun(t *testing.T) {
+	args := []string{"testdata/shard1.json", "testdata/shard2.json"}
+	t.Run("stdout", func(t *testing.T) {
+		cmd := &cobra.Command{}
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		if err := mergeRun(cmd, args); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		report, err := readReportFrom(t, buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, expect := report.Result.String(), "failed"; got != expect {
+			t.Errorf("expected result %s but got %s", expect, got)
+		}
+		if got, expect := len(report.Files), 2; got != expect {
+			t.Errorf("expected %d files but got %d", expect, got)
+		}
+	})
+	t.Run("files", func(t *testing.T) {
+		dir := t.TempDir()
+		jsonOutput = filepath.Join(dir, "report.json")
+		junitOutput = filepath.Join(dir, "junit.xml")
+		defer func() {
+			jsonOutput = ""
+			junitOutput = ""
+		}()
+		cmd := &cobra.Command{}
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		if err := mergeRun(cmd, args); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if buf.Len() > 0 {
+			t.Errorf("unexpected output: %s", buf.String())
+		}
+		if _, err := readReport(jsonOutput); err != nil {
+			t.Fatal(err)
+		}
+		b, err := os.ReadFile(junitOutput)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range []string{
+			`<testsuite tests="1" failures="0" name="scenarios/a.yaml" time="1.000000">`,
+			`<testcase name="b" file="scenarios/b.yaml" time="2.000000">`,
+		} {
+			if !strings.Contains(string(b), s) {
+				t.Errorf("JUnit report does not contain %q:\n%s", s, b)
+			}
+		}
+	})
+	t.Run("failure", func(t *testing.T) {
+		if err := mergeRun(&cobra.Command{}, []string{"testdata/not-found.json"}); err == nil {
+			t.Fatal("expected error but no error")
+		}
+	})
+}
+
+func readReportFrom(t *testing.T, b []byte) (*reporter.TestReport, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		return nil, err
+	}
+	return readReport(path)
+}
@@ -0,0 +1,7 @@
+package report
+
+import "github.com/spf13/cobra"
+
+func Commands() []*cobra.Command {
+	return []*cobra.Command{mergeCmd}
+}
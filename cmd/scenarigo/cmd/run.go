@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	gocontext "context"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/pkg/errors"
@@ -17,10 +22,28 @@ import (
 // ErrTestFailed is the error returned when the test failed.
 var ErrTestFailed = errors.New("test failed")
 
-var verbose bool
+// watchInterval is the interval to check the changes of files in the watch mode.
+const watchInterval = 500 * time.Millisecond
+
+var (
+	verbose  bool
+	failFast bool
+	dryRun   bool
+	watch    bool
+	keepVars bool
+	shuffle  string
+	count    int
+)
 
 func init() {
 	runCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "print verbose log")
+	runCmd.Flags().BoolVar(&failFast, "fail-fast", false, "stop running scenarios after the first failure")
+	runCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the rendered requests without sending them")
+	runCmd.Flags().BoolVar(&watch, "watch", false, "watch the files and rerun the affected scenarios on changes")
+	runCmd.Flags().BoolVar(&keepVars, "keep-vars", false, "keep the variables bound by the previous runs in the watch mode")
+	runCmd.Flags().StringVar(&shuffle, "shuffle", "off", `randomize the order of scenario files and scenarios ("on", "off", or a seed)`)
+	runCmd.Flags().Lookup("shuffle").NoOptDefVal = "on"
+	runCmd.Flags().IntVar(&count, "count", 1, "run each scenario n times")
 	rootCmd.AddCommand(runCmd)
 }
 
@@ -38,21 +61,64 @@ func run(cmd *cobra.Command, args []string) error {
 }
 
 func runWithConfig(cmd *cobra.Command, args []string, configPath string) error {
-	opts := []func(*scenarigo.Runner) error{}
 	cfg, err := loadConfig(configPath)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
-	if cfg != nil {
+	seed, shuffled, err := parseShuffle(shuffle)
+	if err != nil {
+		return err
+	}
+	if shuffled {
+		// print the seed to reproduce the order
+		fmt.Fprintf(cmd.OutOrStdout(), "shuffle seed: %d\n", seed)
+	}
+	var newRunner func(cfg *schema.Config) (*scenarigo.Runner, error)
+	newRunner = func(cfg *schema.Config) (*scenarigo.Runner, error) {
+		opts := []func(*scenarigo.Runner) error{}
+		if cfg != nil {
+			if len(args) > 0 {
+				cfg.Scenarios = nil
+			}
+			opts = append(opts, scenarigo.WithConfig(cfg))
+		}
 		if len(args) > 0 {
-			cfg.Scenarios = nil
+			opts = append(opts, scenarigo.WithScenarios(args...))
 		}
-		opts = append(opts, scenarigo.WithConfig(cfg))
-	}
-	if len(args) > 0 {
-		opts = append(opts, scenarigo.WithScenarios(args...))
+		opts = append(opts, filterOptions()...)
+		varsOpts, err := varsOptions()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, varsOpts...)
+		if failFast {
+			opts = append(opts, scenarigo.WithFailFast(true))
+		}
+		if dryRun {
+			opts = append(opts, scenarigo.WithDryRun(true))
+		}
+		if shuffled {
+			opts = append(opts, scenarigo.WithShuffle(seed))
+		}
+		if count != 1 {
+			opts = append(opts, scenarigo.WithCount(count))
+		}
+		if watch {
+			if keepVars {
+				opts = append(opts, scenarigo.WithKeepVars(true))
+			}
+			// load the configuration file again on changes
+			opts = append(opts, scenarigo.WithReload(func() (*scenarigo.Runner, error) {
+				cfg, err := loadConfig(configPath)
+				if err != nil {
+					return nil, fmt.Errorf("failed to load config: %w", err)
+				}
+				return newRunner(cfg)
+			}))
+		}
+		return scenarigo.NewRunner(opts...)
 	}
-	r, err := scenarigo.NewRunner(opts...)
+	r, err := newRunner(cfg)
 	if err != nil {
 		return err
 	}
@@ -60,7 +126,8 @@ func runWithConfig(cmd *cobra.Command, args []string, configPath string) error {
 	reporterOpts := []reporter.Option{
 		reporter.WithWriter(cmd.OutOrStdout()),
 	}
-	if (cfg != nil && cfg.Output.Verbose) || verbose {
+	// the rendered requests are printed as the verbose log
+	if (cfg != nil && cfg.Output.Verbose) || verbose || dryRun {
 		reporterOpts = append(reporterOpts, reporter.WithVerboseLog())
 	}
 
@@ -72,6 +139,10 @@ func runWithConfig(cmd *cobra.Command, args []string, configPath string) error {
 		reporterOpts = append(reporterOpts, reporter.WithNoColor())
 	}
 
+	if watch {
+		return watchAndRun(cmd, r, reporterOpts)
+	}
+
 	success := reporter.Run(
 		func(rptr reporter.Reporter) {
 			r.Run(context.New(rptr))
@@ -84,6 +155,53 @@ func runWithConfig(cmd *cobra.Command, args []string, configPath string) error {
 	return nil
 }
 
+// watchAndRun runs the scenarios and reruns the affected scenarios on changes until interrupted.
+func watchAndRun(cmd *cobra.Command, r *scenarigo.Runner, reporterOpts []reporter.Option) error {
+	ctx, stop := signal.NotifyContext(gocontext.Background(), os.Interrupt)
+	defer stop()
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	return r.Watch(ctx, watchInterval, func(r *scenarigo.Runner, changed []string) {
+		for _, f := range changed {
+			if rel, err := filepath.Rel(wd, f); err == nil {
+				f = rel
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "changed: %s\n", f)
+			if filepath.Ext(f) == ".so" {
+				fmt.Fprintf(cmd.OutOrStdout(), "plugins can't be reloaded, restart to use the changed plugin %s\n", f)
+			}
+		}
+		reporter.Run(
+			func(rptr reporter.Reporter) {
+				r.Run(context.New(rptr))
+			},
+			reporterOpts...,
+		)
+		fmt.Fprintln(cmd.OutOrStdout(), "watching for changes...")
+	}, func(err error) {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s\n", err)
+		fmt.Fprintln(cmd.OutOrStdout(), "watching for changes...")
+	})
+}
+
+// parseShuffle parses the shuffle flag and returns the seed and whether to shuffle.
+// "on" uses the current time as the seed.
+func parseShuffle(s string) (int64, bool, error) {
+	switch s {
+	case "", "off":
+		return 0, false, nil
+	case "on":
+		return time.Now().UnixNano(), true, nil
+	}
+	seed, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf(`invalid shuffle %q: must be "on", "off", or a seed`, s)
+	}
+	return seed, true, nil
+}
+
 func loadConfig(cfgpath string) (*schema.Config, error) {
 	if cfgpath != "" {
 		return schema.LoadConfig(cfgpath, !color.NoColor)
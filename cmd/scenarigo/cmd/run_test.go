@@ -168,3 +168,51 @@ func TestLoadConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestParseShuffle(t *testing.T) {
+	tests := map[string]struct {
+		s           string
+		expectSeed  int64
+		expectOK    bool
+		expectError bool
+	}{
+		"off": {
+			s: "off",
+		},
+		"empty": {
+			s: "",
+		},
+		"seed": {
+			s:          "1234",
+			expectSeed: 1234,
+			expectOK:   true,
+		},
+		"invalid": {
+			s:           "random",
+			expectError: true,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			seed, ok, err := parseShuffle(test.s)
+			if test.expectError {
+				if err == nil {
+					t.Fatal("no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if seed != test.expectSeed || ok != test.expectOK {
+				t.Errorf("expected (%d, %t) but got (%d, %t)", test.expectSeed, test.expectOK, seed, ok)
+			}
+		})
+	}
+	t.Run("on", func(t *testing.T) {
+		if _, ok, err := parseShuffle("on"); err != nil || !ok {
+			t.Fatalf("expected to shuffle: %t, %v", ok, err)
+		}
+	})
+}
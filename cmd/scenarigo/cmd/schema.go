@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema (scenario|config)",
+	Short: "print the JSON Schema of scenario or configuration files",
+	Long: `Prints the JSON Schema of scenario or configuration files.
+The schema can be used by editors like yaml-language-server to validate and complete YAML files.`,
+	Args:          cobra.ExactValidArgs(1),
+	ValidArgs:     []string{"scenario", "config"},
+	RunE:          printSchema,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
+
+func printSchema(cmd *cobra.Command, args []string) error {
+	var s map[string]interface{}
+	switch args[0] {
+	case "scenario":
+		s = schema.ScenarioJSONSchema()
+	case "config":
+		s = schema.ConfigJSONSchema()
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON Schema: %w", err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(b))
+	return nil
+}
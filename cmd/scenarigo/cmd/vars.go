@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zoncoen/scenarigo"
+)
+
+var (
+	profile   string
+	varsFiles []string
+	varsFlags []string
+	envFiles  []string
+)
+
+func init() {
+	for _, c := range []*cobra.Command{runCmd, lintCmd} {
+		c.Flags().StringVar(&profile, "profile", "", "select the profile defined in the configuration file")
+		c.Flags().StringArrayVar(&varsFiles, "vars-file", nil, "load global variables from a YAML or JSON file")
+		c.Flags().StringArrayVar(&varsFlags, "var", nil, "set a global variable in the form of key=value")
+		c.Flags().StringArrayVar(&envFiles, "env-file", nil, "load environment variables from a .env file")
+	}
+}
+
+// varsOptions returns the options to set global variables.
+// The variables are overridden in the order of the configuration, the profile, the files, and the flags.
+func varsOptions() ([]func(*scenarigo.Runner) error, error) {
+	opts := []func(*scenarigo.Runner) error{
+		scenarigo.WithProfile(profile),
+	}
+	for _, f := range varsFiles {
+		opts = append(opts, scenarigo.WithVarsFile(f))
+	}
+	for _, f := range envFiles {
+		opts = append(opts, scenarigo.WithEnvFile(f))
+	}
+	if len(varsFlags) > 0 {
+		vars := make(map[string]interface{}, len(varsFlags))
+		for _, v := range varsFlags {
+			kv := strings.SplitN(v, "=", 2)
+			if len(kv) != 2 || kv[0] == "" {
+				return nil, fmt.Errorf("invalid --var %q: must be in the form of key=value", v)
+			}
+			vars[kv[0]] = kv[1]
+		}
+		opts = append(opts, scenarigo.WithVars(vars))
+	}
+	return opts, nil
+}
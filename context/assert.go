@@ -1,6 +1,8 @@
 package context
 
 import (
+	"sort"
+
 	"github.com/pkg/errors"
 
 	"github.com/zoncoen/scenarigo/assert"
@@ -20,6 +22,16 @@ var assertions = map[string]interface{}{
 	"length":             assert.Length,
 }
 
+// AssertionNames returns the sorted names of the assertion functions which can be referred by "assert".
+func AssertionNames() []string {
+	names := make([]string, 0, len(assertions))
+	for name := range assertions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func buildArg(base func(assert.Assertion) assert.Assertion) func(interface{}) assert.Assertion {
 	return func(arg interface{}) assert.Assertion {
 		assertion, ok := arg.(assert.Assertion)
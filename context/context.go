@@ -15,10 +15,15 @@ type (
 	keyPluginDir        struct{}
 	keyPlugins          struct{}
 	keyVars             struct{}
+	keyGlobalVars       struct{}
 	keyRequest          struct{}
 	keyResponse         struct{}
+	keyOutputs          struct{}
+	keyIncludeStack     struct{}
+	keyEnv              struct{}
 	keyYAMLNode         struct{}
 	keyEnabledColor     struct{}
+	keyDryRun           struct{}
 )
 
 // Context represents a scenarigo context.
@@ -148,6 +153,50 @@ func (c *Context) WithVars(v interface{}) *Context {
 	)
 }
 
+// WithReadOnlyVars returns a copy of c with v as read-only variables.
+func (c *Context) WithReadOnlyVars(v interface{}) *Context {
+	if v == nil {
+		return c
+	}
+	vars, _ := c.ctx.Value(keyVars{}).(Vars)
+	vars = vars.AppendReadOnly(v)
+	return newContext(
+		context.WithValue(c.ctx, keyVars{}, vars),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// WithoutVars returns a copy of c without the context variables.
+func (c *Context) WithoutVars() *Context {
+	return newContext(
+		context.WithValue(c.ctx, keyVars{}, Vars(nil)),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// WithGlobalVars returns a copy of c which treats the current context variables as the global variables.
+// The global variables are kept by WithoutScenarioVars.
+func (c *Context) WithGlobalVars() *Context {
+	vars, _ := c.ctx.Value(keyVars{}).(Vars)
+	return newContext(
+		context.WithValue(c.ctx, keyGlobalVars{}, vars[:len(vars):len(vars)]),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// WithoutScenarioVars returns a copy of c without the context variables except the global variables.
+func (c *Context) WithoutScenarioVars() *Context {
+	vars, _ := c.ctx.Value(keyGlobalVars{}).(Vars)
+	return newContext(
+		context.WithValue(c.ctx, keyVars{}, vars),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
 // Vars returns the context variables.
 func (c *Context) Vars() Vars {
 	vs, ok := c.ctx.Value(keyVars{}).(Vars)
@@ -157,6 +206,34 @@ func (c *Context) Vars() Vars {
 	return nil
 }
 
+// WithEnv returns a copy of c with environment variables loaded from files.
+func (c *Context) WithEnv(env map[string]string) *Context {
+	if env == nil {
+		return c
+	}
+	merged := map[string]string{}
+	for k, v := range c.Env() {
+		merged[k] = v
+	}
+	for k, v := range env {
+		merged[k] = v
+	}
+	return newContext(
+		context.WithValue(c.ctx, keyEnv{}, merged),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// Env returns the environment variables loaded from files.
+func (c *Context) Env() map[string]string {
+	env, ok := c.ctx.Value(keyEnv{}).(map[string]string)
+	if ok {
+		return env
+	}
+	return nil
+}
+
 // WithRequest returns a copy of c with request.
 func (c *Context) WithRequest(req interface{}) *Context {
 	if req == nil {
@@ -191,6 +268,41 @@ func (c *Context) Response() interface{} {
 	return c.ctx.Value(keyResponse{})
 }
 
+// WithOutputs returns a copy of c with outputs of the included scenario.
+func (c *Context) WithOutputs(outputs interface{}) *Context {
+	if outputs == nil {
+		return c
+	}
+	return newContext(
+		context.WithValue(c.ctx, keyOutputs{}, outputs),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// Outputs returns the outputs of the included scenario.
+func (c *Context) Outputs() interface{} {
+	return c.ctx.Value(keyOutputs{})
+}
+
+// WithIncludeStack returns a copy of c with the filepaths of the scenarios which are including the current one.
+func (c *Context) WithIncludeStack(stack []string) *Context {
+	return newContext(
+		context.WithValue(c.ctx, keyIncludeStack{}, stack),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// IncludeStack returns the filepaths of the scenarios which are including the current one.
+func (c *Context) IncludeStack() []string {
+	stack, ok := c.ctx.Value(keyIncludeStack{}).([]string)
+	if ok {
+		return stack
+	}
+	return nil
+}
+
 // WithNode returns a copy of c with ast.Node.
 func (c *Context) WithNode(node ast.Node) *Context {
 	if node == nil {
@@ -230,6 +342,24 @@ func (c *Context) EnabledColor() bool {
 	return false
 }
 
+// WithDryRun returns a copy of c with dryRun flag.
+func (c *Context) WithDryRun(dryRun bool) *Context {
+	return newContext(
+		context.WithValue(c.ctx, keyDryRun{}, dryRun),
+		c.reqCtx,
+		c.reporter,
+	)
+}
+
+// DryRun returns whether the requests are only built without sending them.
+func (c *Context) DryRun() bool {
+	dryRun, ok := c.ctx.Value(keyDryRun{}).(bool)
+	if ok {
+		return dryRun
+	}
+	return false
+}
+
 // Run runs f as a subtest of c called name.
 func (c *Context) Run(name string, f func(*Context)) bool {
 	return c.Reporter().Run(name, func(r reporter.Reporter) { f(c.WithReporter(r)) })
@@ -5,6 +5,7 @@ import (
 
 	"github.com/goccy/go-yaml/ast"
 	"github.com/goccy/go-yaml/token"
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestContext(t *testing.T) {
@@ -23,6 +24,30 @@ func TestContext(t *testing.T) {
 			t.Fatal("failed to get node")
 		}
 	})
+	t.Run("vars", func(t *testing.T) {
+		ctx := FromT(t).WithVars(map[string]string{"foo": "bar"})
+		if ctx.WithoutVars().Vars() != nil {
+			t.Fatal("failed to reset vars")
+		}
+	})
+	t.Run("global vars", func(t *testing.T) {
+		ctx := FromT(t).WithVars(map[string]string{"foo": "bar"}).WithGlobalVars()
+		ctx = ctx.WithVars(map[string]string{"hoge": "fuga"})
+		vars := ctx.WithoutScenarioVars().Vars()
+		if _, ok := vars.ExtractByKey("foo"); !ok {
+			t.Fatal("global vars are removed")
+		}
+		if _, ok := vars.ExtractByKey("hoge"); ok {
+			t.Fatal("failed to remove scenario vars")
+		}
+	})
+	t.Run("include stack", func(t *testing.T) {
+		stack := []string{"a.yaml", "b.yaml"}
+		ctx := FromT(t).WithIncludeStack(stack)
+		if diff := cmp.Diff(stack, ctx.IncludeStack()); diff != "" {
+			t.Errorf("differs: (-want +got)\n%s", diff)
+		}
+	})
 	t.Run("enabledColor", func(t *testing.T) {
 		ctx := FromT(t)
 		ctx = ctx.WithEnabledColor(true)
@@ -30,4 +55,13 @@ func TestContext(t *testing.T) {
 			t.Fatal("failed to get enabledColor")
 		}
 	})
+	t.Run("dryRun", func(t *testing.T) {
+		ctx := FromT(t)
+		if ctx.DryRun() {
+			t.Fatal("dryRun is enabled by default")
+		}
+		if !ctx.WithDryRun(true).DryRun() {
+			t.Fatal("failed to get dryRun")
+		}
+	})
 }
@@ -4,13 +4,19 @@ import "os"
 
 var env = &envExtractor{}
 
-type envExtractor struct{}
+// envExtractor extracts environment variables.
+// The variables of the process take precedence over the variables loaded from files.
+type envExtractor struct {
+	vars map[string]string
+}
 
 // ExtractByKey implements query.KeyExtractor interface.
 func (f *envExtractor) ExtractByKey(key string) (interface{}, bool) {
-	v, ok := os.LookupEnv(key)
-	if !ok {
-		return nil, false
+	if v, ok := os.LookupEnv(key); ok {
+		return v, true
+	}
+	if v, ok := f.vars[key]; ok {
+		return v, true
 	}
-	return v, true
+	return nil, false
 }
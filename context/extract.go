@@ -6,6 +6,7 @@ const (
 	nameVars     = "vars"
 	nameRequest  = "request"
 	nameResponse = "response"
+	nameOutputs  = "outputs"
 	nameEnv      = "env"
 	nameAssert   = "assert"
 )
@@ -35,7 +36,15 @@ func (c *Context) ExtractByKey(key string) (interface{}, bool) {
 		if v != nil {
 			return v, true
 		}
+	case nameOutputs:
+		v := c.Outputs()
+		if v != nil {
+			return v, true
+		}
 	case nameEnv:
+		if v := c.Env(); v != nil {
+			return &envExtractor{vars: v}, true
+		}
 		return env, true
 	case nameAssert:
 		return assertions, true
@@ -39,6 +39,20 @@ func TestContext_ExtractKey(t *testing.T) {
 			query:  "vars.foo",
 			expect: "bar",
 		},
+		"read-only vars": {
+			ctx: func(ctx *Context) *Context {
+				return ctx.WithReadOnlyVars(vars).WithVars(map[string]string{"foo": "baz"})
+			},
+			query:  "vars.foo",
+			expect: "bar",
+		},
+		"vars which are not read-only": {
+			ctx: func(ctx *Context) *Context {
+				return ctx.WithReadOnlyVars(vars).WithVars(map[string]string{"hoge": "fuga"})
+			},
+			query:  "vars.hoge",
+			expect: "fuga",
+		},
 		"request": {
 			ctx: func(ctx *Context) *Context {
 				return ctx.WithRequest(vars)
@@ -53,10 +67,31 @@ func TestContext_ExtractKey(t *testing.T) {
 			query:  "response.foo",
 			expect: "bar",
 		},
+		"outputs": {
+			ctx: func(ctx *Context) *Context {
+				return ctx.WithOutputs(vars)
+			},
+			query:  "outputs.foo",
+			expect: "bar",
+		},
 		"env": {
 			query:  "env.TEST_PORT",
 			expect: "5000",
 		},
+		"env from file": {
+			ctx: func(ctx *Context) *Context {
+				return ctx.WithEnv(map[string]string{"TEST_HOST": "localhost"})
+			},
+			query:  "env.TEST_HOST",
+			expect: "localhost",
+		},
+		"env takes precedence over file": {
+			ctx: func(ctx *Context) *Context {
+				return ctx.WithEnv(map[string]string{"TEST_PORT": "8000"})
+			},
+			query:  "env.TEST_PORT",
+			expect: "5000",
+		},
 	}
 	for name, test := range tests {
 		test := test
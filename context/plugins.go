@@ -1,5 +1,11 @@
 package context
 
+import (
+	"sort"
+
+	"github.com/zoncoen/query-go"
+)
+
 // Plugins represents plugins.
 type Plugins []map[string]interface{}
 
@@ -22,3 +28,35 @@ func (plugins Plugins) ExtractByKey(key string) (interface{}, bool) {
 	}
 	return nil, false
 }
+
+// Hooks returns the hooks named name of the plugins sorted by the plugin names.
+// If the plugin extracts a value by the key like Go plugins, the value is returned as the hook.
+// Otherwise, the plugin itself is returned to check whether it implements the hook interface.
+func (plugins Plugins) Hooks(name string) []interface{} {
+	ps := map[string]interface{}{}
+	for _, m := range plugins {
+		for k, p := range m {
+			// the former plugins take precedence like ExtractByKey
+			if _, ok := ps[k]; !ok {
+				ps[k] = p
+			}
+		}
+	}
+	names := make([]string, 0, len(ps))
+	for k := range ps {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var hooks []interface{}
+	for _, k := range names {
+		p := ps[k]
+		if e, ok := p.(query.KeyExtractor); ok {
+			if h, ok := e.ExtractByKey(name); ok {
+				hooks = append(hooks, h)
+			}
+			continue
+		}
+		hooks = append(hooks, p)
+	}
+	return hooks
+}
@@ -17,9 +17,46 @@ func (vars Vars) Append(v interface{}) Vars {
 	return vars
 }
 
+// AppendReadOnly appends v to context variables as read-only variables.
+// The read-only variables take precedence over the others, so they can't be overridden.
+func (vars Vars) AppendReadOnly(v interface{}) Vars {
+	if v == nil {
+		return vars
+	}
+	vars = append(vars, readOnlyVars{v})
+	return vars
+}
+
+// ReadOnly reports whether the variable of key is read-only.
+func (vars Vars) ReadOnly(key string) bool {
+	for _, v := range vars {
+		if ro, ok := v.(readOnlyVars); ok {
+			if _, err := query.New().Append(extractor.Key(key)).Extract(ro.vars); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readOnlyVars represents the variables which can't be overridden.
+type readOnlyVars struct {
+	vars interface{}
+}
+
 // ExtractByKey implements query.KeyExtractor interface.
 func (vars Vars) ExtractByKey(key string) (interface{}, bool) {
 	for i := len(vars) - 1; i >= 0; i-- {
+		if ro, ok := vars[i].(readOnlyVars); ok {
+			if v, err := query.New().Append(extractor.Key(key)).Extract(ro.vars); err == nil {
+				return v, true
+			}
+		}
+	}
+	for i := len(vars) - 1; i >= 0; i-- {
+		if _, ok := vars[i].(readOnlyVars); ok {
+			continue
+		}
 		if v, err := query.New().Append(extractor.Key(key)).Extract(vars[i]); err == nil {
 			return v, true
 		}
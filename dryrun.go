@@ -0,0 +1,51 @@
+package scenarigo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/protocol"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+// dryRunRequest renders the request of the step and logs it instead of sending it.
+// The request of the protocol which doesn't implement protocol.RequestBuilder is stubbed out.
+func dryRunRequest(ctx *context.Context, s *schema.Step, stepIdx int) *context.Context {
+	if s.Request.Invoker == nil {
+		return ctx
+	}
+	builder, ok := s.Request.Invoker.(protocol.RequestBuilder)
+	if !ok {
+		ctx.Reporter().Logf("request: %s protocol doesn't support the dry-run mode", s.Protocol)
+		return ctx
+	}
+	req, err := builder.BuildRequest(ctx)
+	if err != nil {
+		ctx.Reporter().Fatal(
+			errors.WithNodeAndColored(
+				errors.WithPath(err, fmt.Sprintf("steps[%d].request", stepIdx)),
+				ctx.Node(),
+				ctx.EnabledColor(),
+			),
+		)
+	}
+	b, err := yaml.Marshal(req)
+	if err != nil {
+		ctx.Reporter().Logf("failed to dump request:\n%s", err)
+		return ctx
+	}
+	ctx.Reporter().Logf("request:\n%s", indent(string(b)))
+	return ctx
+}
+
+func indent(s string) string {
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = "  " + l
+	}
+	return strings.Join(lines, "\n")
+}
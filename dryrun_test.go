@@ -0,0 +1,113 @@
+package scenarigo
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/plugin"
+	"github.com/zoncoen/scenarigo/reporter"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+func TestRunner_DryRun(t *testing.T) {
+	var sent bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		sent = true
+	}))
+	defer srv.Close()
+
+	yml := `
+title: dry run
+vars:
+  message: hello
+steps:
+- title: request
+  protocol: http
+  request:
+    method: POST
+    url: '{{env.TEST_ADDR}}/echo'
+    body:
+      message: '{{vars.message}}'
+  expect:
+    code: 200
+  bind:
+    vars:
+      id: '{{response.id}}'
+- title: wait
+  wait: 1h
+- title: plugin
+  ref: '{{plugins.step}}'
+- title: skipped
+  skip: not implemented yet
+  protocol: http
+  request:
+    url: '{{env.TEST_ADDR}}'
+`
+	runner, err := NewRunner(
+		WithScenariosFromReader(strings.NewReader(yml)),
+		WithDryRun(true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("TEST_ADDR", srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("TEST_ADDR")
+	var (
+		b      bytes.Buffer
+		report *reporter.TestReport
+	)
+	reporter.Run(func(rptr reporter.Reporter) {
+		runner.Run(context.New(rptr).WithPlugins(map[string]interface{}{
+			"step": plugin.StepFunc(func(ctx *context.Context, step *schema.Step) *context.Context {
+				t.Error("plugin step must not run")
+				return ctx
+			}),
+		}))
+		report, err = reporter.GenerateTestReport(rptr)
+	}, reporter.WithWriter(&b), reporter.WithVerboseLog())
+	if err != nil {
+		t.Fatalf("failed to generate report: %s", err)
+	}
+	if sent {
+		t.Error("the request must not be sent")
+	}
+
+	got := map[string]reporter.TestResult{}
+	for _, f := range report.Files {
+		for _, scn := range f.Scenarios {
+			got[scn.Name] = scn.Result
+			for _, step := range scn.Steps {
+				got[step.Name] = step.Result
+			}
+		}
+	}
+	expect := map[string]reporter.TestResult{
+		"dry run": reporter.TestResultPassed,
+		"request": reporter.TestResultPassed,
+		"wait":    reporter.TestResultPassed,
+		"plugin":  reporter.TestResultSkipped,
+		"skipped": reporter.TestResultSkipped,
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("differs (-want +got):\n%s\n%s", diff, b.String())
+	}
+	for _, s := range []string{
+		"url: " + srv.URL + "/echo",
+		"message: hello",
+		"wait for 1h0m0s",
+		"plugin steps don't run in the dry-run mode",
+	} {
+		if !strings.Contains(b.String(), s) {
+			t.Errorf("output doesn't contain %q:\n%s", s, b.String())
+		}
+	}
+}
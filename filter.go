@@ -0,0 +1,196 @@
+package scenarigo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/zoncoen/scenarigo/reporter"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+// WithTags returns a option which runs only scenarios that have any of the tags.
+// A scenario also runs if any of its steps has the tags,
+// and then the steps which have only the other tags are skipped unless the scenario has the tags.
+// The steps without tags always run because the following steps may depend on them.
+func WithTags(tags ...string) func(*Runner) error {
+	return func(r *Runner) error {
+		r.tags = append(r.tags, tags...)
+		return nil
+	}
+}
+
+// WithExcludeTags returns a option which skips scenarios that have any of the tags.
+// The steps which have any of the tags are skipped, and the other steps of the scenario run.
+func WithExcludeTags(tags ...string) func(*Runner) error {
+	return func(r *Runner) error {
+		r.excludeTags = append(r.excludeTags, tags...)
+		return nil
+	}
+}
+
+// WithRunPattern returns a option which runs only scenarios that match the regular expression like "go test -run".
+// A scenario matches if the pattern matches its file path, its title, or the title of any of its steps.
+// All steps of a matched scenario run because the following steps may depend on the previous ones.
+func WithRunPattern(pattern string) func(*Runner) error {
+	return func(r *Runner) error {
+		if pattern == "" {
+			return nil
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid run pattern: %w", err)
+		}
+		r.runPattern = re
+		return nil
+	}
+}
+
+// WithRerunFailed returns a option which runs only the failed scenarios in the JSON test report.
+// The scenarios skipped or canceled by the fail fast also run.
+// If a file failed without any failed scenarios like a load error, all scenarios of the file run.
+func WithRerunFailed(path string) func(*Runner) error {
+	return func(r *Runner) error {
+		if path == "" {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to read test report: %w", err)
+		}
+		defer f.Close()
+		var report reporter.TestReport
+		if err := json.NewDecoder(f).Decode(&report); err != nil {
+			return fmt.Errorf("failed to read test report: %w", err)
+		}
+		failed := map[string]map[string]bool{}
+		for _, file := range report.Files {
+			var scns map[string]bool
+			for _, scn := range file.Scenarios {
+				if !rerun(scn) {
+					continue
+				}
+				if scns == nil {
+					scns = map[string]bool{}
+				}
+				scns[scn.Name] = true
+			}
+			if scns == nil && file.Result != reporter.TestResultFailed {
+				continue
+			}
+			failed[file.Name] = scns
+		}
+		r.rerunFailed = failed
+		return nil
+	}
+}
+
+// rerun reports whether the scenario should run again.
+// The scenarios skipped by the fail fast run again because they didn't run to the end.
+func rerun(scn reporter.ScenarioReport) bool {
+	return scn.Result == reporter.TestResultFailed ||
+		(scn.Result == reporter.TestResultSkipped && scn.SkipReason == reporter.SkipReasonFailFast)
+}
+
+func (r *Runner) hasFilter() bool {
+	return len(r.tags) > 0 || len(r.excludeTags) > 0 || r.runPattern != nil || r.rerunFailed != nil
+}
+
+// filterFiles returns the scenario files which contain at least one scenario to run.
+// The files which fail to load are kept to report the error on running.
+func (r *Runner) filterFiles(files []string) []string {
+	if !r.hasFilter() {
+		return files
+	}
+	filtered := []string{}
+	for _, f := range files {
+		testName, err := filepath.Rel(r.rootDir, f)
+		if err != nil {
+			filtered = append(filtered, f)
+			continue
+		}
+		if _, ok := r.rerunFailed[testName]; r.rerunFailed != nil && !ok {
+			continue
+		}
+		scns, err := schema.LoadScenarios(f, schema.WithStepLibrary(r.stepLibrary))
+		if err != nil {
+			filtered = append(filtered, f)
+			continue
+		}
+		if len(r.filterScenarios(testName, scns)) > 0 {
+			filtered = append(filtered, f)
+		}
+	}
+	return filtered
+}
+
+// filterScenarios returns the scenarios to run.
+func (r *Runner) filterScenarios(testName string, scns []*schema.Scenario) []*schema.Scenario {
+	if !r.hasFilter() {
+		return scns
+	}
+	var filtered []*schema.Scenario
+	for _, scn := range scns {
+		if r.matchScenario(testName, scn) {
+			r.skipSteps(scn)
+			filtered = append(filtered, scn)
+		}
+	}
+	return filtered
+}
+
+// skipSteps marks the steps of scn which are filtered out by the tags as skipped.
+func (r *Runner) skipSteps(scn *schema.Scenario) {
+	for _, step := range scn.Steps {
+		if step.Skip != "" || len(step.Tags) == 0 {
+			continue
+		}
+		if len(r.excludeTags) > 0 && containsAny(step.Tags, r.excludeTags) {
+			step.Skip = "skipped by the excluded tags"
+			continue
+		}
+		if len(r.tags) > 0 && !containsAny(scn.Tags, r.tags) && !containsAny(step.Tags, r.tags) {
+			step.Skip = "skipped because the step doesn't have the tags"
+		}
+	}
+}
+
+func (r *Runner) matchScenario(testName string, scn *schema.Scenario) bool {
+	if r.rerunFailed != nil {
+		scns, ok := r.rerunFailed[testName]
+		if !ok || (scns != nil && !scns[scn.Title]) {
+			return false
+		}
+	}
+	if len(r.excludeTags) > 0 && containsAny(scn.Tags, r.excludeTags) {
+		return false
+	}
+	if len(r.tags) > 0 && !containsAny(scn.AllTags(), r.tags) {
+		return false
+	}
+	if r.runPattern != nil {
+		if r.runPattern.MatchString(testName) || r.runPattern.MatchString(scn.Title) {
+			return true
+		}
+		for _, step := range scn.Steps {
+			if r.runPattern.MatchString(step.Title) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func containsAny(tags, targets []string) bool {
+	for _, tag := range tags {
+		for _, target := range targets {
+			if tag == target {
+				return true
+			}
+		}
+	}
+	return false
+}
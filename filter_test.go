@@ -0,0 +1,165 @@
+package scenarigo
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+func TestRunner_matchScenario(t *testing.T) {
+	scn := &schema.Scenario{
+		Title: "echo",
+		Tags:  []string{"smoke"},
+		Steps: []*schema.Step{
+			{
+				Title: "POST /echo",
+				Tags:  []string{"slow"},
+			},
+		},
+	}
+	tests := map[string]struct {
+		opts   []func(*Runner) error
+		expect bool
+	}{
+		"no filter": {
+			expect: true,
+		},
+		"tags": {
+			opts:   []func(*Runner) error{WithTags("smoke")},
+			expect: true,
+		},
+		"tags of step": {
+			opts:   []func(*Runner) error{WithTags("slow")},
+			expect: true,
+		},
+		"tags not found": {
+			opts:   []func(*Runner) error{WithTags("nightly")},
+			expect: false,
+		},
+		"exclude tags": {
+			opts:   []func(*Runner) error{WithExcludeTags("smoke")},
+			expect: false,
+		},
+		"exclude tags takes precedence": {
+			opts:   []func(*Runner) error{WithTags("slow"), WithExcludeTags("smoke")},
+			expect: false,
+		},
+		"exclude tags of step": {
+			opts:   []func(*Runner) error{WithExcludeTags("slow")},
+			expect: true,
+		},
+		"run pattern matches file": {
+			opts:   []func(*Runner) error{WithRunPattern(`^scenarios/echo\.yaml$`)},
+			expect: true,
+		},
+		"run pattern matches title": {
+			opts:   []func(*Runner) error{WithRunPattern("^echo$")},
+			expect: true,
+		},
+		"run pattern matches step title": {
+			opts:   []func(*Runner) error{WithRunPattern("POST")},
+			expect: true,
+		},
+		"run pattern not matched": {
+			opts:   []func(*Runner) error{WithRunPattern("GET")},
+			expect: false,
+		},
+		"rerun failed scenario": {
+			opts:   []func(*Runner) error{WithRerunFailed("testdata/report/failed.json")},
+			expect: true,
+		},
+		"rerun failed file": {
+			opts:   []func(*Runner) error{WithRerunFailed("testdata/report/load-error.json")},
+			expect: true,
+		},
+		"rerun skipped by fail fast": {
+			opts:   []func(*Runner) error{WithRerunFailed("testdata/report/fail-fast.json")},
+			expect: true,
+		},
+		"rerun but skipped": {
+			opts:   []func(*Runner) error{WithRerunFailed("testdata/report/skipped.json")},
+			expect: false,
+		},
+		"rerun but passed": {
+			opts:   []func(*Runner) error{WithRerunFailed("testdata/report/passed.json")},
+			expect: false,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			r, err := NewRunner(test.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := r.matchScenario("scenarios/echo.yaml", scn); got != test.expect {
+				t.Errorf("expect %t but got %t", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestWithRunPattern(t *testing.T) {
+	if _, err := NewRunner(WithRunPattern("(")); err == nil {
+		t.Fatal("expected error but no error")
+	}
+}
+
+func TestWithRerunFailed(t *testing.T) {
+	if _, err := NewRunner(WithRerunFailed("testdata/report/not-found.json")); err == nil {
+		t.Fatal("expected error but no error")
+	}
+}
+
+func TestRunner_skipSteps(t *testing.T) {
+	tests := map[string]struct {
+		opts   []func(*Runner) error
+		tags   []string
+		expect []string
+	}{
+		"no filter": {
+			expect: []string{"", "", ""},
+		},
+		"tags of step": {
+			opts:   []func(*Runner) error{WithTags("slow")},
+			expect: []string{"", "", "skipped because the step doesn't have the tags"},
+		},
+		"tags of scenario": {
+			opts:   []func(*Runner) error{WithTags("smoke")},
+			tags:   []string{"smoke"},
+			expect: []string{"", "", ""},
+		},
+		"exclude tags of step": {
+			opts:   []func(*Runner) error{WithExcludeTags("slow")},
+			expect: []string{"", "skipped by the excluded tags", ""},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			r, err := NewRunner(test.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			scn := &schema.Scenario{
+				Title: "echo",
+				Tags:  test.tags,
+				Steps: []*schema.Step{
+					{Title: "login"},
+					{Title: "POST /echo", Tags: []string{"slow"}},
+					{Title: "GET /echo", Tags: []string{"fast"}},
+				},
+			}
+			r.skipSteps(scn)
+			var got []string
+			for _, step := range scn.Steps {
+				got = append(got, step.Skip)
+			}
+			if diff := cmp.Diff(test.expect, got); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
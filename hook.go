@@ -0,0 +1,57 @@
+package scenarigo
+
+import (
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/plugin"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+// beforeScenario calls the BeforeScenario hooks of the plugins.
+func beforeScenario(ctx *context.Context, s *schema.Scenario) *context.Context {
+	for _, h := range ctx.Plugins().Hooks("BeforeScenario") {
+		switch f := h.(type) {
+		case plugin.BeforeScenario:
+			ctx = f.BeforeScenario(ctx, s)
+		case func(*context.Context, *schema.Scenario) *context.Context:
+			ctx = f(ctx, s)
+		}
+	}
+	return ctx
+}
+
+// afterScenario calls the AfterScenario hooks of the plugins.
+func afterScenario(ctx *context.Context, s *schema.Scenario) {
+	for _, h := range ctx.Plugins().Hooks("AfterScenario") {
+		switch f := h.(type) {
+		case plugin.AfterScenario:
+			f.AfterScenario(ctx, s)
+		case func(*context.Context, *schema.Scenario):
+			f(ctx, s)
+		}
+	}
+}
+
+// beforeStep calls the BeforeStep hooks of the plugins.
+func beforeStep(ctx *context.Context, s *schema.Step) *context.Context {
+	for _, h := range ctx.Plugins().Hooks("BeforeStep") {
+		switch f := h.(type) {
+		case plugin.BeforeStep:
+			ctx = f.BeforeStep(ctx, s)
+		case func(*context.Context, *schema.Step) *context.Context:
+			ctx = f(ctx, s)
+		}
+	}
+	return ctx
+}
+
+// afterStep calls the AfterStep hooks of the plugins.
+func afterStep(ctx *context.Context, s *schema.Step) {
+	for _, h := range ctx.Plugins().Hooks("AfterStep") {
+		switch f := h.(type) {
+		case plugin.AfterStep:
+			f.AfterStep(ctx, s)
+		case func(*context.Context, *schema.Step):
+			f(ctx, s)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package scenarigo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	gplugin "plugin"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/plugin"
+	"github.com/zoncoen/scenarigo/reporter"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+type symbols map[string]interface{}
+
+func (s symbols) Lookup(name string) (gplugin.Symbol, error) {
+	if sym, ok := s[name]; ok {
+		return sym, nil
+	}
+	return nil, errors.New("not found")
+}
+
+type recorder struct {
+	m      sync.Mutex
+	events []string
+}
+
+func (r *recorder) record(format string, args ...interface{}) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.events = append(r.events, fmt.Sprintf(format, args...))
+}
+
+type hooks struct {
+	*recorder
+}
+
+func (h *hooks) BeforeScenario(ctx *context.Context, s *schema.Scenario) *context.Context {
+	h.record("before scenario: %s", s.Title)
+	return ctx.WithVars(map[string]string{"traceID": "xxx"})
+}
+
+func (h *hooks) AfterScenario(ctx *context.Context, s *schema.Scenario) {
+	h.record("after scenario: %s", s.Title)
+}
+
+func (h *hooks) BeforeStep(ctx *context.Context, s *schema.Step) *context.Context {
+	h.record("before step: %s", s.Title)
+	return ctx
+}
+
+func (h *hooks) AfterStep(ctx *context.Context, s *schema.Step) {
+	h.record("after step: %s", s.Title)
+}
+
+func TestRunner_Hooks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"traceID": %q}`, req.Header.Get("X-Trace-Id"))
+	}))
+	defer srv.Close()
+	if err := os.Setenv("TEST_ADDR", srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("TEST_ADDR")
+
+	yml := `
+title: hooks
+steps:
+- title: request
+  protocol: http
+  request:
+    url: '{{env.TEST_ADDR}}'
+  expect:
+    body:
+      traceID: xxx
+- title: failed
+  ref: '{{plugins.fail}}'
+`
+	runner, err := NewRunner(WithScenariosFromReader(strings.NewReader(yml)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &recorder{}
+	var b bytes.Buffer
+	reporter.Run(func(rptr reporter.Reporter) {
+		runner.Run(context.New(rptr).WithPlugins(map[string]interface{}{
+			"hooks": &hooks{rec},
+			// Go plugins export the hooks as functions
+			"http": &plug{symbols{
+				"OnRequest": func(ctx *context.Context, req interface{}) error {
+					traceID, _ := ctx.Vars().ExtractByKey("traceID")
+					req.(*http.Request).Header.Set("X-Trace-Id", fmt.Sprint(traceID))
+					rec.record("on request")
+					return nil
+				},
+				"OnResponse": plugin.OnResponseFunc(func(ctx *context.Context, resp interface{}) error {
+					rec.record("on response: %d", resp.(*http.Response).StatusCode)
+					return nil
+				}),
+			}},
+			"fail": plugin.StepFunc(func(ctx *context.Context, step *schema.Step) *context.Context {
+				ctx.Reporter().FailNow()
+				return ctx
+			}),
+		}))
+	}, reporter.WithWriter(&b))
+
+	expect := []string{
+		"before scenario: hooks",
+		"before step: request",
+		"on request",
+		"on response: 200",
+		"after step: request",
+		"before step: failed",
+		"after step: failed",
+		"after scenario: hooks",
+	}
+	if diff := cmp.Diff(expect, rec.events); diff != "" {
+		t.Errorf("differs (-want +got):\n%s\n%s", diff, b.String())
+	}
+	if !strings.Contains(b.String(), "--- FAIL: 0/hooks/failed") {
+		t.Errorf("the step must fail:\n%s", b.String())
+	}
+}
+
+func TestRunner_Hooks_Fatal(t *testing.T) {
+	tests := map[string]struct {
+		yaml   string
+		expect []string
+	}{
+		"invalid scenario vars": {
+			yaml: `
+title: invalid vars
+vars:
+  id: '{{vars.notFound}}'
+steps:
+- title: step
+  ref: '{{plugins.pass}}'
+`,
+			expect: []string{
+				"before scenario: invalid vars",
+				"after scenario: invalid vars",
+			},
+		},
+		"invalid step": {
+			yaml: `
+title: invalid step
+steps:
+- title: step
+  ref: '{{plugins.notFound}}'
+`,
+			expect: []string{
+				"before scenario: invalid step",
+				"before step: step",
+				"after step: step",
+				"after scenario: invalid step",
+			},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			runner, err := NewRunner(WithScenariosFromReader(strings.NewReader(test.yaml)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec := &recorder{}
+			var b bytes.Buffer
+			ok := reporter.Run(func(rptr reporter.Reporter) {
+				runner.Run(context.New(rptr).WithPlugins(map[string]interface{}{
+					"hooks": &hooks{rec},
+					"pass": plugin.StepFunc(func(ctx *context.Context, step *schema.Step) *context.Context {
+						return ctx
+					}),
+				}))
+			}, reporter.WithWriter(&b))
+			if ok {
+				t.Fatalf("expected failure:\n%s", b.String())
+			}
+			if diff := cmp.Diff(test.expect, rec.events); diff != "" {
+				t.Errorf("differs (-want +got):\n%s\n%s", diff, b.String())
+			}
+		})
+	}
+}
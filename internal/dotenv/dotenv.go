@@ -0,0 +1,94 @@
+// Package dotenv provides a parser of .env files.
+package dotenv
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ReadFile reads the .env file and returns the environment variables.
+func ReadFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse parses the content of .env file.
+// Each line has the form of KEY=VALUE (optionally prefixed with "export"),
+// and lines starting with "#" are ignored as comments.
+// The value can be quoted with single or double quotes, and escape sequences are interpreted in double quotes.
+func Parse(r io.Reader) (map[string]string, error) {
+	env := map[string]string{}
+	s := bufio.NewScanner(r)
+	var n int
+	for s.Scan() {
+		n++
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("line %d: must be in the form of KEY=VALUE", n)
+		}
+		key := strings.TrimSpace(kv[0])
+		if key == "" {
+			return nil, fmt.Errorf("line %d: empty key", n)
+		}
+		v, err := parseValue(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		env[key] = v
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+func parseValue(v string) (string, error) {
+	if v == "" {
+		return "", nil
+	}
+	switch v[0] {
+	case '"':
+		end := closingQuote(v)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value %s", v)
+		}
+		return strconv.Unquote(v[:end+1])
+	case '\'':
+		end := strings.IndexByte(v[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value %s", v)
+		}
+		return v[1 : end+1], nil
+	}
+	// strip the inline comment
+	if i := strings.Index(v, " #"); i >= 0 {
+		v = v[:i]
+	}
+	return strings.TrimSpace(v), nil
+}
+
+// closingQuote returns the index of the closing double quote of v.
+func closingQuote(v string) int {
+	for i := 1; i < len(v); i++ {
+		switch v[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,75 @@
+package dotenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		tests := map[string]struct {
+			in     string
+			expect map[string]string
+		}{
+			"empty": {
+				in:     "",
+				expect: map[string]string{},
+			},
+			"simple": {
+				in: `
+# comment
+FOO=foo
+export BAR = bar
+EMPTY=
+`,
+				expect: map[string]string{
+					"FOO":   "foo",
+					"BAR":   "bar",
+					"EMPTY": "",
+				},
+			},
+			"quoted": {
+				in: `
+DOUBLE="hello\nworld" # comment
+SINGLE='hello\nworld'
+HASH=a#b # comment
+`,
+				expect: map[string]string{
+					"DOUBLE": "hello\nworld",
+					"SINGLE": `hello\nworld`,
+					"HASH":   "a#b",
+				},
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				got, err := Parse(strings.NewReader(test.in))
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if diff := cmp.Diff(test.expect, got); diff != "" {
+					t.Errorf("differs (-want +got):\n%s", diff)
+				}
+			})
+		}
+	})
+	t.Run("failure", func(t *testing.T) {
+		tests := map[string]string{
+			"no value":            "FOO",
+			"empty key":           "=foo",
+			"unterminated double": `FOO="foo`,
+			"unterminated single": `FOO='foo`,
+		}
+		for name, in := range tests {
+			in := in
+			t.Run(name, func(t *testing.T) {
+				if _, err := Parse(strings.NewReader(in)); err == nil {
+					t.Fatal("expected error but no error")
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInvalidRequest = -32600
+)
+
+// request represents a JSON-RPC request or notification.
+// ID is nil if it is a notification.
+type request struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+// response represents a JSON-RPC response.
+type response struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+// notification represents a JSON-RPC notification sent from the server.
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *responseError) Error() string {
+	return e.Message
+}
+
+// conn reads and writes JSON-RPC messages with the base protocol of LSP.
+// Each message has the Content-Length header and the JSON content.
+type conn struct {
+	r  *textproto.Reader
+	br *bufio.Reader
+
+	m sync.Mutex
+	w io.Writer
+}
+
+func newConn(r io.Reader, w io.Writer) *conn {
+	br := bufio.NewReader(r)
+	return &conn{
+		r:  textproto.NewReader(br),
+		br: br,
+		w:  w,
+	}
+}
+
+// read reads the next message.
+func (c *conn) read() ([]byte, error) {
+	header, err := c.r.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length: %w", err)
+	}
+	b := make([]byte, length)
+	if _, err := io.ReadFull(c.br, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// write writes v as a message.
+func (c *conn) write(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n", len(b)); err != nil {
+		return err
+	}
+	_, err = c.w.Write(b)
+	return err
+}
+
+func (c *conn) reply(id *json.RawMessage, result interface{}, err error) error {
+	res := &response{
+		JSONRPC: "2.0",
+		ID:      id,
+	}
+	if err != nil {
+		rerr, ok := err.(*responseError)
+		if !ok {
+			rerr = &responseError{Code: codeInvalidRequest, Message: err.Error()}
+		}
+		res.Error = rerr
+		return c.write(res)
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	res.Result = b
+	return c.write(res)
+}
+
+func (c *conn) notify(method string, params interface{}) error {
+	return c.write(&notification{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	})
+}
@@ -0,0 +1,494 @@
+// Package lsp implements a language server for scenario files.
+package lsp
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	yamlast "github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/token"
+
+	"github.com/zoncoen/scenarigo"
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/schema"
+	"github.com/zoncoen/scenarigo/template/ast"
+	"github.com/zoncoen/scenarigo/template/parser"
+	"github.com/zoncoen/scenarigo/version"
+)
+
+const serverName = "scenarigo"
+
+var (
+	// YAML errors have the positions like "[1:1] unknown field".
+	yamlErrorPosPattern = regexp.MustCompile(`\[(\d+):(\d+)\]`)
+	includePattern      = regexp.MustCompile(`^\s*(?:-\s+)?include:\s*["']?([^"'#\s]+)`)
+	completionPattern   = regexp.MustCompile(`\b(vars|assert)\.(\w*)$`)
+)
+
+// Server is a language server for scenario files.
+// It provides diagnostics by the lint, completion of variables and assertion functions,
+// go-to-definition of included files, and hover to show the AST of templates.
+type Server struct {
+	runner   *scenarigo.Runner
+	conn     *conn
+	docs     map[string]string
+	shutdown bool
+}
+
+// NewServer returns a new language server.
+// The runner is used to lint scenarios with the configuration.
+func NewServer(r *scenarigo.Runner) *Server {
+	return &Server{
+		runner: r,
+		docs:   map[string]string{},
+	}
+}
+
+// Serve reads requests from r and writes responses to w until it receives the exit notification.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+	for {
+		b, err := s.conn.read()
+		if err != nil {
+			if stderrors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		var req request
+		if err := json.Unmarshal(b, &req); err != nil {
+			if err := s.conn.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return stderrors.New("exit without shutdown")
+			}
+			return nil
+		}
+		result, err := s.handle(&req)
+		if req.ID == nil {
+			// notifications don't have responses
+			continue
+		}
+		if err := s.conn.reply(req.ID, result, err); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(req *request) (interface{}, error) {
+	switch req.Method {
+	case "initialize":
+		return &InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync: TextDocumentSyncKindFull,
+				CompletionProvider: &CompletionOptions{
+					TriggerCharacters: []string{"."},
+				},
+				DefinitionProvider: true,
+				HoverProvider:      true,
+			},
+			ServerInfo: ServerInfo{
+				Name:    serverName,
+				Version: version.String(),
+			},
+		}, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := unmarshalParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		s.docs[params.TextDocument.URI] = params.TextDocument.Text
+		return nil, s.publishDiagnostics(params.TextDocument.URI)
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := unmarshalParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			s.docs[params.TextDocument.URI] = params.ContentChanges[n-1].Text
+		}
+		return nil, s.publishDiagnostics(params.TextDocument.URI)
+	case "textDocument/didSave":
+		var params DidCloseTextDocumentParams
+		if err := unmarshalParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.publishDiagnostics(params.TextDocument.URI)
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := unmarshalParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		delete(s.docs, params.TextDocument.URI)
+		return nil, s.conn.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.completion(params.TextDocument.URI, params.Position), nil
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.definition(params.TextDocument.URI, params.Position), nil
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(params.TextDocument.URI, params.Position), nil
+	case "initialized":
+		return nil, nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+}
+
+func unmarshalParams(b json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(b, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// publishDiagnostics lints the document and sends the errors as diagnostics.
+func (s *Server) publishDiagnostics(uri string) error {
+	_, errs := s.runner.LintFile(uriToPath(uri), strings.NewReader(s.docs[uri]))
+	diags := make([]Diagnostic, 0, len(errs))
+	for _, err := range errs {
+		rng, msg := errorRange(s.docs[uri], err)
+		diags = append(diags, Diagnostic{
+			Range:    rng,
+			Severity: DiagnosticSeverityError,
+			Source:   serverName,
+			Message:  msg,
+		})
+	}
+	return s.conn.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: diags,
+	})
+}
+
+// errorRange returns the range and the message of err in text.
+func errorRange(text string, err error) (Range, string) {
+	var pe *errors.PathError
+	if stderrors.As(err, &pe) {
+		if node := lookupNode(pe.Node, pe.Path); node != nil {
+			return tokenRange(text, node.GetToken()), pe.Err.Error()
+		}
+		return Range{}, pe.Err.Error()
+	}
+	msg := strings.SplitN(err.Error(), "\n", 2)[0]
+	if m := yamlErrorPosPattern.FindStringSubmatch(msg); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		col, _ := strconv.Atoi(m[2])
+		pos := Position{Line: line - 1, Character: columnToCharacter(lineAt(text, line-1), col)}
+		return Range{Start: pos, End: pos}, msg
+	}
+	return Range{}, msg
+}
+
+// completion returns the names of the variables defined before the position or the assertion functions.
+func (s *Server) completion(uri string, pos Position) []CompletionItem {
+	m := completionPattern.FindStringSubmatch(linePrefix(s.docs[uri], pos))
+	if m == nil {
+		return []CompletionItem{}
+	}
+	var (
+		names []string
+		kind  int
+	)
+	switch m[1] {
+	case "assert":
+		names = context.AssertionNames()
+		kind = CompletionItemKindFunction
+	case "vars":
+		names = s.varNames(uri, pos)
+		kind = CompletionItemKindVariable
+	}
+	items := []CompletionItem{}
+	for _, name := range names {
+		if !strings.HasPrefix(name, m[2]) {
+			continue
+		}
+		items = append(items, CompletionItem{
+			Label:  name,
+			Kind:   kind,
+			Detail: fmt.Sprintf("%s.%s", m[1], name),
+		})
+	}
+	return items
+}
+
+// varNames returns the names of the variables which can be referred at the position.
+func (s *Server) varNames(uri string, pos Position) []string {
+	seen := map[string]struct{}{}
+	var names []string
+	add := func(ns []string) {
+		for _, n := range ns {
+			if _, ok := seen[n]; ok {
+				continue
+			}
+			seen[n] = struct{}{}
+			names = append(names, n)
+		}
+	}
+	add(s.runner.VarNames())
+
+	scns, _ := s.runner.LintFile(uriToPath(uri), strings.NewReader(s.docs[uri]))
+	line := pos.Line + 1
+	var scn *schema.Scenario
+	for _, sc := range scns {
+		if nodeLine(sc.Node) <= line {
+			scn = sc
+		}
+	}
+	if scn == nil {
+		return names
+	}
+	idx := -1
+	if steps, ok := lookupNode(scn.Node, ".steps").(*yamlast.SequenceNode); ok {
+		for i, step := range steps.Values {
+			if nodeLine(step) <= line {
+				idx = i
+			}
+		}
+	}
+	if outputs := lookupNode(scn.Node, ".outputs"); outputs != nil && nodeLine(outputs) <= line && idx == len(scn.Steps)-1 {
+		idx = len(scn.Steps)
+	}
+	if idx >= 0 {
+		add(scn.DefinedVars(idx))
+	}
+	return names
+}
+
+// definition returns the location of the included file at the position.
+func (s *Server) definition(uri string, pos Position) *Location {
+	m := includePattern.FindStringSubmatch(lineAt(s.docs[uri], pos.Line))
+	if m == nil {
+		return nil
+	}
+	path := filepath.Join(filepath.Dir(uriToPath(uri)), m[1])
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	return &Location{URI: pathToURI(path)}
+}
+
+// hover returns the AST of the template parameter at the position.
+func (s *Server) hover(uri string, pos Position) *Hover {
+	l := lineAt(s.docs[uri], pos.Line)
+	ch := byteOffset(l, pos.Character)
+	start := strings.LastIndex(l[:ch], "{{")
+	if start < 0 || strings.Contains(l[start:ch], "}}") {
+		return nil
+	}
+	end := strings.Index(l[start:], "}}")
+	if end < 0 {
+		return nil
+	}
+	str := l[start : start+end+2]
+	node, err := parser.NewParser(strings.NewReader(str)).Parse()
+	if err != nil {
+		return &Hover{
+			Contents: MarkupContent{
+				Kind:  "markdown",
+				Value: fmt.Sprintf("invalid template `%s`: %s", str, err),
+			},
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "template `%s`\n\n```\n", str)
+	printAST(&b, node, 0)
+	b.WriteString("```\n")
+	return &Hover{
+		Contents: MarkupContent{
+			Kind:  "markdown",
+			Value: b.String(),
+		},
+		Range: &Range{
+			Start: Position{Line: pos.Line, Character: character(l, start)},
+			End:   Position{Line: pos.Line, Character: character(l, start+end+2)},
+		},
+	}
+}
+
+// printAST prints the types of the AST nodes as a tree.
+func printAST(w io.Writer, node ast.Node, depth int) {
+	indent := strings.Repeat("  ", depth)
+	switch n := node.(type) {
+	case *ast.ParameterExpr:
+		fmt.Fprintf(w, "%s%T\n", indent, n)
+		if n.X != nil {
+			printAST(w, n.X, depth+1)
+		}
+	case *ast.BinaryExpr:
+		fmt.Fprintf(w, "%s%T %s\n", indent, n, n.Op)
+		printAST(w, n.X, depth+1)
+		printAST(w, n.Y, depth+1)
+	case *ast.BasicLit:
+		fmt.Fprintf(w, "%s%T %s %q\n", indent, n, n.Kind, n.Value)
+	case *ast.Ident:
+		fmt.Fprintf(w, "%s%T %s\n", indent, n, n.Name)
+	case *ast.SelectorExpr:
+		fmt.Fprintf(w, "%s%T .%s\n", indent, n, n.Sel.Name)
+		printAST(w, n.X, depth+1)
+	case *ast.IndexExpr:
+		fmt.Fprintf(w, "%s%T\n", indent, n)
+		printAST(w, n.X, depth+1)
+		printAST(w, n.Index, depth+1)
+	case *ast.CallExpr:
+		fmt.Fprintf(w, "%s%T\n", indent, n)
+		printAST(w, n.Fun, depth+1)
+		for _, arg := range n.Args {
+			printAST(w, arg, depth+1)
+		}
+	case *ast.LeftArrowExpr:
+		fmt.Fprintf(w, "%s%T\n", indent, n)
+		printAST(w, n.Fun, depth+1)
+		printAST(w, n.Arg, depth+1)
+	default:
+		fmt.Fprintf(w, "%s%T\n", indent, n)
+	}
+}
+
+// lookupNode returns the node of path like ".steps[0].request".
+func lookupNode(node yamlast.Node, path string) yamlast.Node {
+	if node == nil {
+		return nil
+	}
+	p, err := yaml.PathString("$" + path)
+	if err != nil {
+		return nil
+	}
+	n, err := p.FilterNode(node)
+	if err != nil {
+		return nil
+	}
+	return n
+}
+
+// nodeLine returns the one-based line number where node starts.
+func nodeLine(node yamlast.Node) int {
+	if m, ok := node.(*yamlast.MappingNode); ok && len(m.Values) > 0 {
+		return nodeLine(m.Values[0])
+	}
+	if m, ok := node.(*yamlast.MappingValueNode); ok {
+		return nodeLine(m.Key)
+	}
+	tk := node.GetToken()
+	if tk == nil || tk.Position == nil {
+		return 0
+	}
+	return tk.Position.Line
+}
+
+// tokenRange returns the range of tk in text.
+func tokenRange(text string, tk *token.Token) Range {
+	if tk == nil || tk.Position == nil {
+		return Range{}
+	}
+	l := lineAt(text, tk.Position.Line-1)
+	start := Position{Line: tk.Position.Line - 1, Character: columnToCharacter(l, tk.Position.Column)}
+	end := start
+	end.Character += character(tk.Value, len(tk.Value))
+	if tk.Type == token.DoubleQuoteType || tk.Type == token.SingleQuoteType {
+		end.Character += 2
+	}
+	return Range{Start: start, End: end}
+}
+
+func lineAt(text string, n int) string {
+	lines := strings.Split(text, "\n")
+	if n < 0 || n >= len(lines) {
+		return ""
+	}
+	return strings.TrimSuffix(lines[n], "\r")
+}
+
+func linePrefix(text string, pos Position) string {
+	l := lineAt(text, pos.Line)
+	return l[:byteOffset(l, pos.Character)]
+}
+
+// byteOffset returns the byte offset in l of the character offset.
+// The character offsets of LSP are counted in UTF-16 code units.
+func byteOffset(l string, char int) int {
+	n := 0
+	for i, r := range l {
+		if n >= char {
+			return i
+		}
+		n += utf16Len(r)
+	}
+	return len(l)
+}
+
+// character returns the character offset in UTF-16 code units of the byte offset in l.
+func character(l string, offset int) int {
+	if offset > len(l) {
+		offset = len(l)
+	}
+	n := 0
+	for _, r := range l[:offset] {
+		n += utf16Len(r)
+	}
+	return n
+}
+
+// columnToCharacter returns the character offset of the one-based column in runes which the YAML parser reports.
+func columnToCharacter(l string, col int) int {
+	i := 0
+	for offset := range l {
+		if i == col-1 {
+			return character(l, offset)
+		}
+		i++
+	}
+	return character(l, len(l)) + col - 1 - i
+}
+
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func pathToURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
@@ -0,0 +1,338 @@
+package lsp
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	yamlparser "github.com/goccy/go-yaml/parser"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo"
+)
+
+type testClient struct {
+	t    *testing.T
+	conn *conn
+	id   int
+	msgs chan *message
+}
+
+type message struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+}
+
+func startServer(t *testing.T) (*testClient, chan error) {
+	t.Helper()
+	r, err := scenarigo.NewRunner(scenarigo.WithVars(map[string]interface{}{"baseURL": "http://localhost"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cr, sw := io.Pipe()
+	sr, cw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- NewServer(r).Serve(sr, sw)
+		sw.Close()
+	}()
+	c := &testClient{t: t, conn: newConn(cr, cw), msgs: make(chan *message, 100)}
+	// read messages concurrently not to block the server
+	go func() {
+		defer close(c.msgs)
+		for {
+			b, err := c.conn.read()
+			if err != nil {
+				return
+			}
+			var msg message
+			if err := json.Unmarshal(b, &msg); err != nil {
+				return
+			}
+			c.msgs <- &msg
+		}
+	}()
+	return c, done
+}
+
+func (c *testClient) call(method string, params interface{}, result interface{}) {
+	c.t.Helper()
+	c.id++
+	id := json.RawMessage(strings.TrimSpace(string(mustMarshal(c.t, c.id))))
+	if err := c.conn.write(&request{JSONRPC: "2.0", ID: &id, Method: method, Params: mustMarshal(c.t, params)}); err != nil {
+		c.t.Fatal(err)
+	}
+	for {
+		msg := c.read()
+		if msg.ID == nil {
+			// ignore notifications
+			continue
+		}
+		if *msg.ID != c.id {
+			c.t.Fatalf("unexpected response id %d", *msg.ID)
+		}
+		if msg.Error != nil {
+			c.t.Fatalf("%s failed: %s", method, msg.Error.Message)
+		}
+		if result != nil {
+			if err := json.Unmarshal(msg.Result, result); err != nil {
+				c.t.Fatal(err)
+			}
+		}
+		return
+	}
+}
+
+func (c *testClient) notify(method string, params interface{}) {
+	c.t.Helper()
+	if err := c.conn.write(&request{JSONRPC: "2.0", Method: method, Params: mustMarshal(c.t, params)}); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *testClient) read() *message {
+	c.t.Helper()
+	msg, ok := <-c.msgs
+	if !ok {
+		c.t.Fatal("connection closed")
+	}
+	return msg
+}
+
+func mustMarshal(t *testing.T, v interface{}) json.RawMessage {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestServer(t *testing.T) {
+	c, done := startServer(t)
+	var initResult InitializeResult
+	c.call("initialize", map[string]interface{}{}, &initResult)
+	if !initResult.Capabilities.HoverProvider || !initResult.Capabilities.DefinitionProvider {
+		t.Fatalf("unexpected capabilities: %+v", initResult.Capabilities)
+	}
+	c.notify("initialized", map[string]interface{}{})
+
+	uri := pathToURI("testdata/scenario.yaml")
+	text := `title: scenario
+steps:
+- title: get
+  protocol: http
+  request:
+    method: GET
+    url: "{{vars.baseURL}}/{{vars.undefined}}"
+`
+	c.notify("textDocument/didOpen", &DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: uri, Text: text},
+	})
+	msg := c.read()
+	if msg.Method != "textDocument/publishDiagnostics" {
+		t.Fatalf("expected diagnostics but got %s", msg.Method)
+	}
+	var diags PublishDiagnosticsParams
+	if err := json.Unmarshal(msg.Params, &diags); err != nil {
+		t.Fatal(err)
+	}
+	expectDiags := []Diagnostic{
+		{
+			Range: Range{
+				Start: Position{Line: 6, Character: 9},
+				End:   Position{Line: 6, Character: 46},
+			},
+			Severity: DiagnosticSeverityError,
+			Source:   serverName,
+			Message:  `variable "undefined" is not defined`,
+		},
+	}
+	if diff := cmp.Diff(expectDiags, diags.Diagnostics); diff != "" {
+		t.Errorf("diagnostics differ (-want +got):\n%s", diff)
+	}
+
+	c.call("shutdown", nil, nil)
+	c.notify("exit", nil)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestServer_Features(t *testing.T) {
+	c, done := startServer(t)
+	c.call("initialize", map[string]interface{}{}, nil)
+	uri := pathToURI("testdata/scenario.yaml")
+	text, err := readFile("testdata/scenario.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.notify("textDocument/didOpen", &DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: uri, Text: text},
+	})
+
+	t.Run("completion", func(t *testing.T) {
+		tests := map[string]struct {
+			pos    Position
+			expect []string
+		}{
+			"first step": {
+				pos:    Position{Line: 8, Character: 17},
+				expect: []string{"baseURL", "user"},
+			},
+			"after include": {
+				pos:    Position{Line: 20, Character: 17},
+				expect: []string{"baseURL", "token", "user"},
+			},
+			"assert": {
+				pos:    Position{Line: 23, Character: 25},
+				expect: []string{"notContains", "notZero"},
+			},
+			"not template": {
+				pos: Position{Line: 0, Character: 5},
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				var items []CompletionItem
+				c.call("textDocument/completion", &TextDocumentPositionParams{
+					TextDocument: TextDocumentIdentifier{URI: uri},
+					Position:     test.pos,
+				}, &items)
+				var got []string
+				for _, item := range items {
+					got = append(got, item.Label)
+				}
+				if diff := cmp.Diff(test.expect, got); diff != "" {
+					t.Errorf("differs (-want +got):\n%s", diff)
+				}
+			})
+		}
+	})
+
+	t.Run("definition", func(t *testing.T) {
+		var loc *Location
+		c.call("textDocument/definition", &TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: uri},
+			Position:     Position{Line: 15, Character: 12},
+		}, &loc)
+		if loc == nil {
+			t.Fatal("location not found")
+		}
+		if expect := pathToURI("testdata/included.yaml"); loc.URI != expect {
+			t.Errorf("expected %s but got %s", expect, loc.URI)
+		}
+	})
+
+	t.Run("hover", func(t *testing.T) {
+		var hover *Hover
+		c.call("textDocument/hover", &TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: uri},
+			Position:     Position{Line: 8, Character: 14},
+		}, &hover)
+		if hover == nil {
+			t.Fatal("hover not found")
+		}
+		expect := "template `{{vars.baseURL}}`\n\n```\n*ast.ParameterExpr\n  *ast.SelectorExpr .baseURL\n    *ast.Ident vars\n```\n"
+		if diff := cmp.Diff(expect, hover.Contents.Value); diff != "" {
+			t.Errorf("differs (-want +got):\n%s", diff)
+		}
+	})
+
+	c.call("shutdown", nil, nil)
+	c.notify("exit", nil)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func readFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	return string(b), err
+}
+
+func TestCharacterOffset(t *testing.T) {
+	// "é" is 2 bytes and 1 UTF-16 code unit, and "😀" is 4 bytes and 2 UTF-16 code units.
+	l := `  title: "é😀 {{vars.name}}"`
+	tests := map[string]struct {
+		char   int
+		offset int
+	}{
+		"ascii": {
+			char:   2,
+			offset: 2,
+		},
+		"after two bytes character": {
+			char:   11,
+			offset: 12,
+		},
+		"after surrogate pair": {
+			char:   13,
+			offset: 16,
+		},
+		"end": {
+			char:   len(l) - 3,
+			offset: len(l),
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			if got := byteOffset(l, test.char); got != test.offset {
+				t.Errorf("expect byte offset %d but got %d", test.offset, got)
+			}
+			if got := character(l, test.offset); got != test.char {
+				t.Errorf("expect character %d but got %d", test.char, got)
+			}
+		})
+	}
+}
+
+func TestServer_MultibyteLine(t *testing.T) {
+	uri := "file:///scenario.yaml"
+	s := NewServer(nil)
+	s.docs[uri] = "title: \"é😀 {{vars.name}}\"\nsteps: []\n"
+
+	t.Run("hover", func(t *testing.T) {
+		hover := s.hover(uri, Position{Line: 0, Character: 14})
+		if hover == nil {
+			t.Fatal("hover not found")
+		}
+		expect := &Range{
+			Start: Position{Line: 0, Character: 12},
+			End:   Position{Line: 0, Character: 25},
+		}
+		if diff := cmp.Diff(expect, hover.Range); diff != "" {
+			t.Errorf("differs (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("completion", func(t *testing.T) {
+		if got := linePrefix(s.docs[uri], Position{Line: 0, Character: 18}); got != `title: "é😀 {{vars` {
+			t.Errorf("unexpected prefix %q", got)
+		}
+	})
+	t.Run("token", func(t *testing.T) {
+		text := "{a: é😀, b: c}\n"
+		f, err := yamlparser.ParseBytes([]byte(text), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		node := lookupNode(f.Docs[0].Body, ".b")
+		if node == nil {
+			t.Fatal("node not found")
+		}
+		expect := Range{
+			Start: Position{Line: 0, Character: 12},
+			End:   Position{Line: 0, Character: 13},
+		}
+		if diff := cmp.Diff(expect, tokenRange(text, node.GetToken())); diff != "" {
+			t.Errorf("differs (-want +got):\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,193 @@
+package scenarigo
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+// Lint validates all scenarios statically without running them and returns all errors found.
+// It also reports the variables which are referred but never defined.
+// The errors contain the YAML source positions of the invalid fields.
+func (r *Runner) Lint() []error {
+	var errs []error
+	// the library steps are validated once instead of each scenario which uses them
+	libs, _ := getAllFiles(r.stepLibraryPaths...)
+	for _, f := range libs {
+		name, err := filepath.Rel(r.rootDir, f)
+		if err != nil {
+			name = f
+		}
+		for _, err := range schema.ValidateStepLibrary(f, r.enabledColor) {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	files := r.ScenarioFiles()
+	scopes := r.includeScopes(files...)
+	for _, f := range files {
+		name, err := filepath.Rel(r.rootDir, f)
+		if err != nil {
+			name = f
+		}
+		scns, err := schema.LoadScenarios(f, schema.WithStepLibrary(r.stepLibrary))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		for _, scn := range r.filterScenarios(name, scns) {
+			for _, err := range r.lintScenario(scn, scopes) {
+				errs = append(errs, fmt.Errorf("%s: %w", name, errors.WithNodeAndColored(err, scn.Node, r.enabledColor)))
+			}
+		}
+	}
+	return errs
+}
+
+// LintFile loads the scenarios from src and validates them statically like Lint.
+// path is the file path of src which is used to resolve the relative paths like included files.
+// Unlike Lint, it returns the loaded scenarios and the errors of *errors.PathError have the nodes of the scenarios without colors,
+// so the caller like editors can get the positions from their paths.
+func (r *Runner) LintFile(path string, src io.Reader) ([]*schema.Scenario, []error) {
+	scns, err := schema.LoadScenariosFromReader(src, schema.WithStepLibrary(r.stepLibrary), schema.WithFilepath(path))
+	if err != nil {
+		return nil, []error{err}
+	}
+	scopes := r.includeScopes(path)
+	var errs []error
+	for _, scn := range scns {
+		for _, err := range r.lintScenario(scn, scopes) {
+			errs = append(errs, errors.WithNodeAndColored(err, scn.Node, false))
+		}
+	}
+	return scns, errs
+}
+
+// lintScenario validates scn.
+// If scn is included by the other scenarios, the inputs given by "with" or the variables of the callers are treated as defined variables.
+func (r *Runner) lintScenario(scn *schema.Scenario, scopes map[string][]string) []error {
+	var pluginDir string
+	if r.pluginDir != nil {
+		pluginDir = *r.pluginDir
+	}
+	defined := r.VarNames()
+	if path, err := filepath.Abs(scn.Filepath()); err == nil {
+		defined = append(defined, scopes[path]...)
+	}
+	errs := append(scn.Validate(pluginDir), scn.UndefinedVars(defined...)...)
+	return append(errs, readOnlyVarErrors(scn, r.setupVarNames())...)
+}
+
+// readOnlyVarErrors returns the errors of the variables which override the read-only variables bound by the setup scenarios.
+func readOnlyVarErrors(scn *schema.Scenario, readOnly []string) []error {
+	if len(readOnly) == 0 {
+		return nil
+	}
+	ro := map[string]bool{}
+	for _, name := range readOnly {
+		ro[name] = true
+	}
+	var errs []error
+	check := func(path string, vars map[string]interface{}) {
+		names := make([]string, 0, len(vars))
+		for name := range vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if ro[name] {
+				errs = append(errs, errors.ErrorPathf(fmt.Sprintf("%s.%s", path, name), "variable %q is bound by the setup scenarios and can't be overridden", name))
+			}
+		}
+	}
+	check("vars", scn.Vars)
+	for i, step := range scn.Steps {
+		check(fmt.Sprintf("steps[%d].vars", i), step.Vars)
+		check(fmt.Sprintf("steps[%d].bind.vars", i), step.Bind.Vars)
+	}
+	return errs
+}
+
+// includeScopes returns the names of the variables which each file included by the scenario files can refer to.
+// The other files in the directories of paths are also searched, so the variables of the included file linted alone are found.
+// The keys are the absolute paths of the included files, and the files which fail to load are ignored.
+func (r *Runner) includeScopes(paths ...string) map[string][]string {
+	files := map[string]struct{}{}
+	for _, f := range r.scenarioFiles {
+		files[f] = struct{}{}
+	}
+	for _, p := range paths {
+		dir, err := filepath.Abs(filepath.Dir(p))
+		if err != nil {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() && looksLikeYAML(e.Name()) {
+				files[filepath.Join(dir, e.Name())] = struct{}{}
+			}
+		}
+	}
+	scopes := map[string][]string{}
+	for f := range files {
+		scns, err := schema.LoadScenarios(f, schema.WithStepLibrary(r.stepLibrary))
+		if err != nil {
+			continue
+		}
+		for _, scn := range scns {
+			for path, names := range scn.IncludeScopes() {
+				abs, err := filepath.Abs(path)
+				if err != nil {
+					continue
+				}
+				scopes[abs] = append(scopes[abs], names...)
+			}
+		}
+	}
+	return scopes
+}
+
+// VarNames returns the names of the global variables.
+// The variables of all profiles are included because any profile may be selected on running.
+// The variables bound by the setup scenarios are also included.
+func (r *Runner) VarNames() []string {
+	var names []string
+	for name := range r.vars {
+		names = append(names, name)
+	}
+	for _, p := range r.profiles {
+		for name := range p.Vars {
+			names = append(names, name)
+		}
+	}
+	names = append(names, r.setupVarNames()...)
+	sort.Strings(names)
+	return names
+}
+
+// setupVarNames returns the names of the variables bound by the steps of the setup scenarios.
+// The setup files which fail to load are ignored because they are reported on running.
+func (r *Runner) setupVarNames() []string {
+	var names []string
+	for _, f := range r.setupFiles {
+		scns, err := schema.LoadScenarios(f, schema.WithStepLibrary(r.stepLibrary))
+		if err != nil {
+			continue
+		}
+		for _, scn := range scns {
+			for _, step := range scn.Steps {
+				for name := range step.Bind.Vars {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	return names
+}
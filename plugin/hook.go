@@ -0,0 +1,85 @@
+package plugin
+
+import (
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/protocol"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+// The hooks are called for the plugins declared in the scenario or the configuration.
+// A Go plugin provides the hook by exporting the function or the variable named as the method of the hook interface,
+// for example, "func BeforeScenario(ctx *plugin.Context, s *schema.Scenario) *plugin.Context".
+
+// BeforeScenario is the hook called before running each scenario.
+type BeforeScenario interface {
+	BeforeScenario(*context.Context, *schema.Scenario) *context.Context
+}
+
+// BeforeScenarioFunc is an adaptor to allow the use of ordinary functions as BeforeScenario hook.
+type BeforeScenarioFunc func(ctx *context.Context, s *schema.Scenario) *context.Context
+
+// BeforeScenario implements BeforeScenario interface.
+func (f BeforeScenarioFunc) BeforeScenario(ctx *context.Context, s *schema.Scenario) *context.Context {
+	return f(ctx, s)
+}
+
+// AfterScenario is the hook called after running each scenario even if the scenario failed.
+type AfterScenario interface {
+	AfterScenario(*context.Context, *schema.Scenario)
+}
+
+// AfterScenarioFunc is an adaptor to allow the use of ordinary functions as AfterScenario hook.
+type AfterScenarioFunc func(ctx *context.Context, s *schema.Scenario)
+
+// AfterScenario implements AfterScenario interface.
+func (f AfterScenarioFunc) AfterScenario(ctx *context.Context, s *schema.Scenario) {
+	f(ctx, s)
+}
+
+// BeforeStep is the hook called before running each step.
+type BeforeStep interface {
+	BeforeStep(*context.Context, *schema.Step) *context.Context
+}
+
+// BeforeStepFunc is an adaptor to allow the use of ordinary functions as BeforeStep hook.
+type BeforeStepFunc func(ctx *context.Context, step *schema.Step) *context.Context
+
+// BeforeStep implements BeforeStep interface.
+func (f BeforeStepFunc) BeforeStep(ctx *context.Context, step *schema.Step) *context.Context {
+	return f(ctx, step)
+}
+
+// AfterStep is the hook called after running each step even if the step failed.
+type AfterStep interface {
+	AfterStep(*context.Context, *schema.Step)
+}
+
+// AfterStepFunc is an adaptor to allow the use of ordinary functions as AfterStep hook.
+type AfterStepFunc func(ctx *context.Context, step *schema.Step)
+
+// AfterStep implements AfterStep interface.
+func (f AfterStepFunc) AfterStep(ctx *context.Context, step *schema.Step) {
+	f(ctx, step)
+}
+
+// OnRequest is the hook called before sending each request.
+type OnRequest = protocol.OnRequest
+
+// OnRequestFunc is an adaptor to allow the use of ordinary functions as OnRequest hook.
+type OnRequestFunc func(ctx *context.Context, req interface{}) error
+
+// OnRequest implements OnRequest interface.
+func (f OnRequestFunc) OnRequest(ctx *context.Context, req interface{}) error {
+	return f(ctx, req)
+}
+
+// OnResponse is the hook called after receiving each response.
+type OnResponse = protocol.OnResponse
+
+// OnResponseFunc is an adaptor to allow the use of ordinary functions as OnResponse hook.
+type OnResponseFunc func(ctx *context.Context, resp interface{}) error
+
+// OnResponse implements OnResponse interface.
+func (f OnResponseFunc) OnResponse(ctx *context.Context, resp interface{}) error {
+	return f(ctx, resp)
+}
@@ -0,0 +1,263 @@
+package rpcplugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zoncoen/scenarigo/assert"
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/plugin"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+// closeTimeout is the time to wait for the plugin process to exit after closing its input.
+const closeTimeout = 5 * time.Second
+
+// Plugin represents an out-of-process plugin.
+type Plugin struct {
+	client *rpc.Client
+	cmd    *exec.Cmd
+
+	m       sync.Mutex
+	symbols map[string]interface{}
+	missing map[string]error
+}
+
+// Open starts the plugin executable of path and connects to it.
+func Open(path string) (*Plugin, error) {
+	cmd := exec.Command(path)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plugin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plugin: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to open plugin: %w", err)
+	}
+	p := NewPlugin(&stdio{r: stdout, w: stdin})
+	p.cmd = cmd
+	return p, nil
+}
+
+// NewPlugin returns a plugin which talks with the plugin served by ServeConn over conn.
+func NewPlugin(conn io.ReadWriteCloser) *Plugin {
+	return &Plugin{
+		client:  rpc.NewClientWithCodec(jsonrpc.NewClientCodec(conn)),
+		symbols: map[string]interface{}{},
+		missing: map[string]error{},
+	}
+}
+
+// Close closes the connection and waits for the plugin process to exit.
+func (p *Plugin) Close() error {
+	err := p.client.Close()
+	if p.cmd == nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- p.cmd.Wait()
+	}()
+	select {
+	case <-done:
+	case <-time.After(closeTimeout):
+		_ = p.cmd.Process.Kill()
+		<-done
+	}
+	return err
+}
+
+// Lookup returns the exported value named name.
+// The functions are returned as "func(...interface{}) (interface{}, error)",
+// the steps as plugin.Step, and the assertions as assert.Assertion.
+func (p *Plugin) Lookup(name string) (interface{}, error) {
+	p.m.Lock()
+	v, ok := p.symbols[name]
+	err := p.missing[name]
+	p.m.Unlock()
+	if ok {
+		return v, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sym Symbol
+	if err := p.client.Call(serviceName+".Lookup", name, &sym); err != nil {
+		// cache the missing names because the hooks are looked up for every step
+		var serverErr rpc.ServerError
+		if errors.As(err, &serverErr) {
+			p.m.Lock()
+			p.missing[name] = err
+			p.m.Unlock()
+		}
+		return nil, err
+	}
+	v, err = p.value(&sym)
+	if err != nil {
+		return nil, err
+	}
+	// the values are not cached because they may change
+	if sym.Kind != kindValue {
+		p.m.Lock()
+		p.symbols[name] = v
+		p.m.Unlock()
+	}
+	return v, nil
+}
+
+// ExtractByKey implements query.KeyExtractor interface.
+func (p *Plugin) ExtractByKey(key string) (interface{}, bool) {
+	v, err := p.Lookup(key)
+	if err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+func (p *Plugin) value(sym *Symbol) (interface{}, error) {
+	switch sym.Kind {
+	case kindValue:
+		return decode(sym.Value)
+	case kindFunc:
+		return p.function(sym.ID), nil
+	case kindStep:
+		return &step{plugin: p, id: sym.ID}, nil
+	case kindAssertion:
+		return p.assertion(sym.ID), nil
+	default:
+		return nil, fmt.Errorf("unknown symbol kind %q", sym.Kind)
+	}
+}
+
+func (p *Plugin) function(id string) func(...interface{}) (interface{}, error) {
+	return func(args ...interface{}) (interface{}, error) {
+		req := &CallRequest{
+			ID:   id,
+			Args: make([]json.RawMessage, len(args)),
+		}
+		for i, arg := range args {
+			b, err := json.Marshal(arg)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal argument %d: %w", i, err)
+			}
+			req.Args[i] = b
+		}
+		var sym Symbol
+		if err := p.client.Call(serviceName+".Call", req, &sym); err != nil {
+			return nil, err
+		}
+		return p.value(&sym)
+	}
+}
+
+func (p *Plugin) assertion(id string) assert.Assertion {
+	return assert.AssertionFunc(func(v interface{}) error {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to marshal value: %w", err)
+		}
+		var resp AssertResponse
+		if err := p.client.Call(serviceName+".Assert", &AssertRequest{ID: id, Value: b}, &resp); err != nil {
+			return err
+		}
+		if resp.Error != "" {
+			return errors.New(resp.Error)
+		}
+		return nil
+	})
+}
+
+var _ plugin.Step = &step{}
+
+// step implements plugin.Step interface.
+type step struct {
+	plugin *Plugin
+	id     string
+}
+
+// Run implements plugin.Step interface.
+func (s *step) Run(ctx *context.Context, st *schema.Step) *context.Context {
+	req := &StepRequest{
+		ID:    s.id,
+		Title: st.Title,
+	}
+	if st.Vars != nil {
+		vars, err := ctx.ExecuteTemplate(st.Vars)
+		if err != nil {
+			ctx.Reporter().Fatalf("invalid vars: %s", err)
+		}
+		req.Vars = vars
+	}
+	var resp StepResponse
+	if err := s.plugin.client.Call(serviceName+".RunStep", req, &resp); err != nil {
+		ctx.Reporter().Fatal(err)
+	}
+	for _, l := range resp.Logs {
+		ctx.Reporter().Log(l)
+	}
+	if resp.Vars != nil {
+		ctx = ctx.WithVars(normalize(resp.Vars))
+	}
+	if resp.Response != nil {
+		ctx = ctx.WithResponse(normalize(resp.Response))
+	}
+	return ctx
+}
+
+// decode decodes the JSON value.
+// The integers are decoded as int64 unlike encoding/json to compare them with the integers in YAML.
+func decode(b json.RawMessage) (interface{}, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, fmt.Errorf("failed to decode value: %w", err)
+	}
+	return normalize(v), nil
+}
+
+// normalize converts the numbers decoded from JSON into int64 or float64.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case float64:
+		if i := int64(v); float64(i) == v {
+			return i
+		}
+		return v
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalize(e)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalize(e)
+		}
+		return v
+	default:
+		return v
+	}
+}
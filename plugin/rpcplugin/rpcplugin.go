@@ -0,0 +1,73 @@
+// Package rpcplugin provides the out-of-process plugins which run as child processes.
+//
+// An out-of-process plugin is an executable which calls Serve in its main function.
+// scenarigo starts the executable and talks JSON-RPC with it over the standard input and output,
+// so the plugin can be built by any version of Go unlike Go plugins.
+//
+//	func main() {
+//		rpcplugin.Serve(map[string]interface{}{
+//			"Version": "v1.0.0",
+//			"Greet": func(name string) string {
+//				return fmt.Sprintf("Hello, %s!", name)
+//			},
+//		})
+//	}
+//
+// The exported values are available in templates like "{{plugins.name.Greet("scenarigo")}}".
+// The values, the arguments, and the results of functions must be able to be marshaled into JSON.
+package rpcplugin
+
+import (
+	"encoding/json"
+)
+
+// serviceName is the name of the RPC service which the plugin serves.
+const serviceName = "Plugin"
+
+// kinds of symbols
+const (
+	kindValue     = "value"
+	kindFunc      = "func"
+	kindStep      = "step"
+	kindAssertion = "assertion"
+)
+
+// Symbol represents an exported value of the plugin.
+// The functions, the steps, and the assertions are referred by the IDs because they can't be marshaled into JSON.
+type Symbol struct {
+	Kind  string          `json:"kind"`
+	ID    string          `json:"id,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// CallRequest represents a request to call the function.
+type CallRequest struct {
+	ID   string            `json:"id"`
+	Args []json.RawMessage `json:"args"`
+}
+
+// StepRequest represents a request to run the step.
+type StepRequest struct {
+	ID    string      `json:"id,omitempty"`
+	Title string      `json:"title,omitempty"`
+	Vars  interface{} `json:"vars,omitempty"`
+}
+
+// StepResponse represents a result of the step.
+// Vars are added to the variables, and Response can be referred by "response" in the bind of the step.
+type StepResponse struct {
+	Vars     map[string]interface{} `json:"vars,omitempty"`
+	Response interface{}            `json:"response,omitempty"`
+	Logs     []string               `json:"logs,omitempty"`
+}
+
+// AssertRequest represents a request to assert the value.
+type AssertRequest struct {
+	ID    string          `json:"id"`
+	Value json.RawMessage `json:"value"`
+}
+
+// AssertResponse represents a result of the assertion.
+type AssertResponse struct {
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,174 @@
+package rpcplugin
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/assert"
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/plugin"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+func newTestPlugin(t *testing.T, exports map[string]interface{}) *Plugin {
+	t.Helper()
+	srvConn, cliConn := net.Pipe()
+	go ServeConn(srvConn, exports)
+	p := NewPlugin(cliConn)
+	t.Cleanup(func() { p.Close() })
+	return p
+}
+
+func TestPlugin_Lookup(t *testing.T) {
+	p := newTestPlugin(t, map[string]interface{}{
+		"String": "string",
+		"Int":    1,
+		"Float":  1.5,
+		"Map": map[string]interface{}{
+			"ids": []int{1, 2},
+		},
+		"Greet": func(name string, names ...string) string {
+			for _, n := range names {
+				name = fmt.Sprintf("%s and %s", name, n)
+			}
+			return fmt.Sprintf("Hello, %s!", name)
+		},
+		"Add": func(a, b int) int { return a + b },
+		"Fail": func() (string, error) {
+			return "", errors.New("failed")
+		},
+		"IsEven": AssertionFunc(func(v interface{}) error {
+			if n, ok := v.(float64); ok && int(n)%2 == 0 {
+				return nil
+			}
+			return fmt.Errorf("%v is not even", v)
+		}),
+		"GreaterThan": func(n int) Assertion {
+			return AssertionFunc(func(v interface{}) error {
+				if f, ok := v.(float64); ok && f > float64(n) {
+					return nil
+				}
+				return fmt.Errorf("%v is not greater than %d", v, n)
+			})
+		},
+	})
+
+	t.Run("values", func(t *testing.T) {
+		tests := map[string]interface{}{
+			"String": "string",
+			"Int":    int64(1),
+			"Float":  1.5,
+			"Map": map[string]interface{}{
+				"ids": []interface{}{int64(1), int64(2)},
+			},
+		}
+		for name, expect := range tests {
+			got, err := p.Lookup(name)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(expect, got); diff != "" {
+				t.Errorf("%s differs (-want +got):\n%s", name, diff)
+			}
+		}
+	})
+	t.Run("functions", func(t *testing.T) {
+		call := func(name string, args ...interface{}) (interface{}, error) {
+			t.Helper()
+			f, ok := p.ExtractByKey(name)
+			if !ok {
+				t.Fatalf("%s not found", name)
+			}
+			return f.(func(...interface{}) (interface{}, error))(args...)
+		}
+		if got, err := call("Greet", "Alice", "Bob", "Carol"); err != nil || got != "Hello, Alice and Bob and Carol!" {
+			t.Errorf("unexpected result: %v, %v", got, err)
+		}
+		if got, err := call("Add", 1, int64(2)); err != nil || got != int64(3) {
+			t.Errorf("unexpected result: %v, %v", got, err)
+		}
+		if _, err := call("Fail"); err == nil || err.Error() != "failed" {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if _, err := call("Add", "1", 2); err == nil {
+			t.Error("expected error but no error")
+		}
+		if _, err := call("Add", 1); err == nil {
+			t.Error("expected error but no error")
+		}
+	})
+	t.Run("assertions", func(t *testing.T) {
+		v, ok := p.ExtractByKey("IsEven")
+		if !ok {
+			t.Fatal("IsEven not found")
+		}
+		isEven := v.(assert.Assertion)
+		if err := isEven.Assert(2); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		if err := isEven.Assert(3); err == nil {
+			t.Error("expected error but no error")
+		}
+
+		v, ok = p.ExtractByKey("GreaterThan")
+		if !ok {
+			t.Fatal("GreaterThan not found")
+		}
+		gt, err := v.(func(...interface{}) (interface{}, error))(1)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if err := gt.(assert.Assertion).Assert(2); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		if err := gt.(assert.Assertion).Assert(1); err == nil {
+			t.Error("expected error but no error")
+		}
+	})
+	t.Run("not found", func(t *testing.T) {
+		if _, ok := p.ExtractByKey("Unknown"); ok {
+			t.Fatal("found unknown value")
+		}
+	})
+}
+
+func TestPlugin_Step(t *testing.T) {
+	p := newTestPlugin(t, map[string]interface{}{
+		"Login": StepFunc(func(req *StepRequest) (*StepResponse, error) {
+			vars, _ := req.Vars.(map[string]interface{})
+			return &StepResponse{
+				Vars: map[string]interface{}{
+					"user": vars["user"],
+				},
+				Response: map[string]interface{}{
+					"token": "xxx",
+				},
+				Logs: []string{fmt.Sprintf("login: %s", req.Title)},
+			}, nil
+		}),
+	})
+	v, ok := p.ExtractByKey("Login")
+	if !ok {
+		t.Fatal("Login not found")
+	}
+	stp, ok := v.(plugin.Step)
+	if !ok {
+		t.Fatalf("expected plugin.Step but got %T", v)
+	}
+	ctx := stp.Run(context.FromT(t), &schema.Step{
+		Title: "login",
+		Vars: map[string]interface{}{
+			"user": "alice",
+		},
+	})
+	if got, _ := ctx.Vars().ExtractByKey("user"); got != "alice" {
+		t.Errorf("expected alice but got %v", got)
+	}
+	if diff := cmp.Diff(map[string]interface{}{"token": "xxx"}, ctx.Response()); diff != "" {
+		t.Errorf("differs (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,236 @@
+package rpcplugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"reflect"
+	"sync"
+)
+
+// Step represents a step implemented by the plugin.
+type Step interface {
+	Run(*StepRequest) (*StepResponse, error)
+}
+
+// StepFunc is an adaptor to allow the use of ordinary functions as step.
+type StepFunc func(req *StepRequest) (*StepResponse, error)
+
+// Run implements Step interface.
+func (f StepFunc) Run(req *StepRequest) (*StepResponse, error) {
+	return f(req)
+}
+
+// Assertion represents an assertion implemented by the plugin.
+type Assertion interface {
+	Assert(v interface{}) error
+}
+
+// AssertionFunc is an adaptor to allow the use of ordinary functions as assertion.
+type AssertionFunc func(v interface{}) error
+
+// Assert implements Assertion interface.
+func (f AssertionFunc) Assert(v interface{}) error {
+	return f(v)
+}
+
+var typeError = reflect.TypeOf((*error)(nil)).Elem()
+
+// Serve serves the exports over the standard input and output until the input is closed.
+// The standard output is redirected to the standard error not to break the communication,
+// so the plugin can print logs by the fmt package.
+func Serve(exports map[string]interface{}) {
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	ServeConn(&stdio{r: os.Stdin, w: stdout}, exports)
+}
+
+// ServeConn serves the exports over conn until conn is closed.
+func ServeConn(conn io.ReadWriteCloser, exports map[string]interface{}) {
+	srv := rpc.NewServer()
+	svc := &service{
+		exports: exports,
+		objects: map[string]interface{}{},
+	}
+	if err := srv.RegisterName(serviceName, svc); err != nil {
+		panic(fmt.Sprintf("failed to register plugin service: %s", err))
+	}
+	srv.ServeCodec(jsonrpc.NewServerCodec(conn))
+}
+
+type stdio struct {
+	r io.ReadCloser
+	w io.WriteCloser
+}
+
+func (s *stdio) Read(p []byte) (int, error)  { return s.r.Read(p) }
+func (s *stdio) Write(p []byte) (int, error) { return s.w.Write(p) }
+
+func (s *stdio) Close() error {
+	rerr := s.r.Close()
+	if err := s.w.Close(); err != nil {
+		return err
+	}
+	return rerr
+}
+
+// service is the RPC service which provides the exports.
+type service struct {
+	exports map[string]interface{}
+
+	m       sync.Mutex
+	objects map[string]interface{}
+	nextID  int
+}
+
+// Lookup returns the exported value named name.
+func (s *service) Lookup(name string, sym *Symbol) error {
+	v, ok := s.exports[name]
+	if !ok {
+		return fmt.Errorf("%s not found", name)
+	}
+	// the exports use the fixed IDs not to register them every lookup
+	result, err := s.symbol(v, "export/"+name)
+	if err != nil {
+		return err
+	}
+	*sym = *result
+	return nil
+}
+
+// Call calls the function and returns the result.
+func (s *service) Call(req *CallRequest, sym *Symbol) error {
+	obj, err := s.object(req.ID)
+	if err != nil {
+		return err
+	}
+	f := reflect.ValueOf(obj)
+	ft := f.Type()
+	if ft.IsVariadic() {
+		if len(req.Args) < ft.NumIn()-1 {
+			return fmt.Errorf("too few arguments: expected at least %d arguments but got %d", ft.NumIn()-1, len(req.Args))
+		}
+	} else if len(req.Args) != ft.NumIn() {
+		return fmt.Errorf("expected %d arguments but got %d", ft.NumIn(), len(req.Args))
+	}
+	args := make([]reflect.Value, len(req.Args))
+	for i, arg := range req.Args {
+		var t reflect.Type
+		if ft.IsVariadic() && i >= ft.NumIn()-1 {
+			t = ft.In(ft.NumIn() - 1).Elem()
+		} else {
+			t = ft.In(i)
+		}
+		v := reflect.New(t)
+		if err := json.Unmarshal(arg, v.Interface()); err != nil {
+			return fmt.Errorf("invalid argument %d: %w", i, err)
+		}
+		args[i] = v.Elem()
+	}
+
+	results := f.Call(args)
+	if n := len(results); n > 0 && ft.Out(n-1) == typeError {
+		if err, _ := results[n-1].Interface().(error); err != nil {
+			return err
+		}
+		results = results[:n-1]
+	}
+	var result interface{}
+	if len(results) > 0 {
+		result = results[0].Interface()
+	}
+	resultSym, err := s.symbol(result, "")
+	if err != nil {
+		return err
+	}
+	*sym = *resultSym
+	return nil
+}
+
+// RunStep runs the step.
+func (s *service) RunStep(req *StepRequest, resp *StepResponse) error {
+	obj, err := s.object(req.ID)
+	if err != nil {
+		return err
+	}
+	step, ok := obj.(Step)
+	if !ok {
+		return fmt.Errorf("%s is not a step", req.ID)
+	}
+	result, err := step.Run(req)
+	if err != nil {
+		return err
+	}
+	if result != nil {
+		*resp = *result
+	}
+	return nil
+}
+
+// Assert asserts the value.
+// The assertion error is returned by the response to distinguish it from the errors of the RPC.
+func (s *service) Assert(req *AssertRequest, resp *AssertResponse) error {
+	obj, err := s.object(req.ID)
+	if err != nil {
+		return err
+	}
+	assertion, ok := obj.(Assertion)
+	if !ok {
+		return fmt.Errorf("%s is not an assertion", req.ID)
+	}
+	var v interface{}
+	if err := json.Unmarshal(req.Value, &v); err != nil {
+		return fmt.Errorf("invalid value: %w", err)
+	}
+	if err := assertion.Assert(v); err != nil {
+		resp.Error = err.Error()
+	}
+	return nil
+}
+
+// symbol converts v into the symbol.
+// If v is a function, a step, or an assertion, v is registered with id to refer it later.
+// The new ID is assigned if id is empty.
+func (s *service) symbol(v interface{}, id string) (*Symbol, error) {
+	var kind string
+	switch v.(type) {
+	case Step:
+		kind = kindStep
+	case Assertion:
+		kind = kindAssertion
+	default:
+		if v != nil && reflect.TypeOf(v).Kind() == reflect.Func {
+			kind = kindFunc
+		}
+	}
+	if kind == "" {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal value: %w", err)
+		}
+		return &Symbol{Kind: kindValue, Value: b}, nil
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	if id == "" {
+		s.nextID++
+		id = fmt.Sprint(s.nextID)
+	}
+	s.objects[id] = v
+	return &Symbol{Kind: kind, ID: id}, nil
+}
+
+func (s *service) object(id string) (interface{}, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	obj, ok := s.objects[id]
+	if !ok {
+		return nil, errors.New("invalid id")
+	}
+	return obj, nil
+}
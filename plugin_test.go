@@ -4,6 +4,9 @@ package scenarigo
 
 import (
 	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -32,6 +35,99 @@ plugins:
 			t.Fatalf("scenario failed:\n%s", log.String())
 		}
 	})
+	t.Run("plugins for all scenarios", func(t *testing.T) {
+		scenarioYAML := `
+title: use plugin
+steps:
+- title: dump
+  ref: '{{plugins.simple.DumpVarsStep}}'
+  `
+		runner, err := NewRunner(
+			WithScenariosFromReader(strings.NewReader(scenarioYAML)),
+			WithPluginDir("test/e2e/testdata/gen/plugins"),
+			WithPlugins(map[string]string{"simple": "simple.so"}),
+		)
+		if err != nil {
+			t.Fatalf("failed to create runner: %s", err)
+		}
+		var log bytes.Buffer
+		ok := reporter.Run(func(rptr reporter.Reporter) {
+			runner.Run(context.New(rptr))
+		}, reporter.WithWriter(&log))
+		if !ok {
+			t.Fatalf("scenario failed:\n%s", log.String())
+		}
+	})
+	t.Run("out-of-process plugin", func(t *testing.T) {
+		dir := t.TempDir()
+		if out, err := exec.Command("go", "build", "-o", filepath.Join(dir, "rpc"), "./testdata/rpcplugin").CombinedOutput(); err != nil {
+			t.Fatalf("failed to build plugin: %s\n%s", err, out)
+		}
+		scenarioYAML := `
+title: use out-of-process plugin
+plugins:
+  rpc: rpc
+steps:
+- title: login
+  ref: '{{plugins.rpc.Login}}'
+  bind:
+    vars:
+      token: '{{response.token}}'
+- title: check
+  vars:
+    token: '{{vars.token}}'
+    greeting: '{{plugins.rpc.Greet("scenarigo")}}'
+  ref: '{{plugins.rpc.Check}}'
+  `
+		runner, err := NewRunner(
+			WithScenariosFromReader(strings.NewReader(scenarioYAML)),
+			WithPluginDir(dir),
+		)
+		if err != nil {
+			t.Fatalf("failed to create runner: %s", err)
+		}
+		var log bytes.Buffer
+		ok := reporter.Run(func(rptr reporter.Reporter) {
+			runner.Run(context.New(rptr))
+		}, reporter.WithWriter(&log))
+		if !ok {
+			t.Fatalf("scenario failed:\n%s", log.String())
+		}
+		if isSharedObject(filepath.Join(dir, "rpc")) {
+			t.Fatal("executable is detected as a shared object")
+		}
+	})
+	t.Run("Go plugin without extension", func(t *testing.T) {
+		dir := t.TempDir()
+		b, err := os.ReadFile("test/e2e/testdata/gen/plugins/complex.so")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "complex"), b, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		scenarioYAML := `
+plugins:
+  complex: complex
+  `
+		runner, err := NewRunner(
+			WithScenariosFromReader(strings.NewReader(scenarioYAML)),
+			WithPluginDir(dir),
+		)
+		if err != nil {
+			t.Fatalf("failed to create runner: %s", err)
+		}
+		var log bytes.Buffer
+		ok := reporter.Run(func(rptr reporter.Reporter) {
+			runner.Run(context.New(rptr))
+		}, reporter.WithWriter(&log))
+		if !ok {
+			t.Fatalf("scenario failed:\n%s", log.String())
+		}
+		if !isSharedObject(filepath.Join(dir, "complex")) {
+			t.Fatal("Go plugin is not detected as a shared object")
+		}
+	})
 	t.Run("failure", func(t *testing.T) {
 		scenarioYAML := `
 plugins:
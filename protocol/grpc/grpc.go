@@ -3,6 +3,7 @@ package grpc
 import (
 	"bytes"
 	"errors"
+	"reflect"
 
 	"github.com/goccy/go-yaml"
 	"github.com/zoncoen/scenarigo/protocol"
@@ -61,3 +62,13 @@ func (p *GRPC) UnmarshalExpect(b []byte) (protocol.AssertionBuilder, error) {
 
 	return &e, nil
 }
+
+// RequestType implements protocol.TypeProvider interface.
+func (p *GRPC) RequestType() reflect.Type {
+	return reflect.TypeOf(Request{})
+}
+
+// ExpectType implements protocol.TypeProvider interface.
+func (p *GRPC) ExpectType() reflect.Type {
+	return reflect.TypeOf(Expect{})
+}
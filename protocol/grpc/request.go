@@ -2,6 +2,7 @@ package grpc
 
 import (
 	"bytes"
+	gocontext "context"
 	"fmt"
 	"reflect"
 	"strings"
@@ -16,6 +17,7 @@ import (
 	"github.com/zoncoen/scenarigo/context"
 	"github.com/zoncoen/scenarigo/errors"
 	"github.com/zoncoen/scenarigo/internal/reflectutil"
+	"github.com/zoncoen/scenarigo/protocol"
 )
 
 // Request represents a request.
@@ -29,6 +31,23 @@ type Request struct {
 	Body interface{} `yaml:"body,omitempty"`
 }
 
+// OutgoingRequest represents the request passed to the OnRequest hooks of plugins.
+// The hooks can modify the metadata and the message.
+type OutgoingRequest struct {
+	Method   string
+	Metadata metadata.MD
+	Message  proto.Message
+}
+
+// IncomingResponse represents the response passed to the OnResponse hooks of plugins.
+type IncomingResponse struct {
+	Method  string
+	Header  metadata.MD
+	Trailer metadata.MD
+	Message proto.Message
+	Error   error
+}
+
 type response struct {
 	Header  metadata.MD     `yaml:"header,omitempty"`
 	Trailer metadata.MD     `yaml:"trailer,omitempty"`
@@ -55,20 +74,103 @@ func (r *Request) addIndent(s string, indentNum int) string {
 
 // Invoke implements protocol.Invoker interface.
 func (r *Request) Invoke(ctx *context.Context) (*context.Context, interface{}, error) {
+	method, reqCtx, req, err := r.buildRequest(ctx)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	md, _ := metadata.FromOutgoingContext(reqCtx)
+	out := &OutgoingRequest{
+		Method:   r.Method,
+		Metadata: md,
+		Message:  req.(proto.Message),
+	}
+	if err := protocol.RunRequestHooks(ctx, out); err != nil {
+		return ctx, nil, errors.Errorf("failed to run OnRequest hook: %s", err)
+	}
+	if md != nil || len(out.Metadata) > 0 {
+		reqCtx = metadata.NewOutgoingContext(reqCtx, out.Metadata)
+	}
+	req = out.Message
+
+	ctx = ctx.WithRequest(req)
+	if b, err := yaml.Marshal(r.dumpRequest(reqCtx, req)); err == nil {
+		ctx.Reporter().Logf("request:\n%s", r.addIndent(string(b), indentNum))
+	} else {
+		ctx.Reporter().Logf("failed to dump request:\n%s", err)
+	}
+
+	var header, trailer metadata.MD
+	in := []reflect.Value{
+		reflect.ValueOf(reqCtx),
+		reflect.ValueOf(req),
+		reflect.ValueOf(grpc.Header(&header)),
+		reflect.ValueOf(grpc.Trailer(&trailer)),
+	}
+
+	rvalues := method.Call(in)
+	message := rvalues[0].Interface()
+	respMsg, _ := message.(proto.Message)
+	callErr, _ := rvalues[1].Interface().(error)
+	if err := protocol.RunResponseHooks(ctx, &IncomingResponse{
+		Method:  r.Method,
+		Header:  header,
+		Trailer: trailer,
+		Message: respMsg,
+		Error:   callErr,
+	}); err != nil {
+		return ctx, nil, errors.Errorf("failed to run OnResponse hook: %s", err)
+	}
+	resp := response{
+		Header:  header,
+		Trailer: trailer,
+		Message: message,
+		rvalues: rvalues,
+	}
+	ctx = ctx.WithResponse(message)
+	if b, err := yaml.Marshal(resp); err == nil {
+		ctx.Reporter().Logf("response:\n%s", r.addIndent(string(b), indentNum))
+	} else {
+		ctx.Reporter().Logf("failed to dump response:\n%s", err)
+	}
+
+	return ctx, resp, nil
+}
+
+// BuildRequest implements protocol.RequestBuilder interface.
+func (r *Request) BuildRequest(ctx *context.Context) (interface{}, error) {
+	_, reqCtx, req, err := r.buildRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.dumpRequest(reqCtx, req), nil
+}
+
+func (r *Request) dumpRequest(reqCtx gocontext.Context, req interface{}) *Request {
+	reqMD, _ := metadata.FromOutgoingContext(reqCtx)
+	return &Request{
+		Method:   r.Method,
+		Metadata: reqMD,
+		Message:  req,
+	}
+}
+
+// buildRequest returns the method of the client, the context with metadata, and the request message.
+func (r *Request) buildRequest(ctx *context.Context) (reflect.Value, gocontext.Context, interface{}, error) {
 	if r.Client == "" {
-		return ctx, nil, errors.New("gRPC client must be specified")
+		return reflect.Value{}, nil, nil, errors.New("gRPC client must be specified")
 	}
 
 	x, err := ctx.ExecuteTemplate(r.Client)
 	if err != nil {
-		return ctx, nil, errors.WrapPath(err, "client", "failed to get client")
+		return reflect.Value{}, nil, nil, errors.WrapPath(err, "client", "failed to get client")
 	}
 
 	client := reflect.ValueOf(x)
 	var method reflect.Value
 	for {
 		if !client.IsValid() {
-			return nil, nil, errors.ErrorPathf("client", "client %s is invalid", r.Client)
+			return reflect.Value{}, nil, nil, errors.ErrorPathf("client", "client %s is invalid", r.Client)
 		}
 		method = client.MethodByName(r.Method)
 		if method.IsValid() {
@@ -79,23 +181,23 @@ func (r *Request) Invoke(ctx *context.Context) (*context.Context, interface{}, e
 		case reflect.Interface, reflect.Ptr:
 			client = client.Elem()
 		default:
-			return nil, nil, errors.ErrorPathf("method", "method %s.%s not found", r.Client, r.Method)
+			return reflect.Value{}, nil, nil, errors.ErrorPathf("method", "method %s.%s not found", r.Client, r.Method)
 		}
 	}
 
 	if err := validateMethod(method); err != nil {
-		return ctx, nil, errors.ErrorPathf("method", `"%s.%s" must be "func(context.Context, proto.Message, ...grpc.CallOption) (proto.Message, error): %s"`, r.Client, r.Method, err)
+		return reflect.Value{}, nil, nil, errors.ErrorPathf("method", `"%s.%s" must be "func(context.Context, proto.Message, ...grpc.CallOption) (proto.Message, error): %s"`, r.Client, r.Method, err)
 	}
 
 	reqCtx := ctx.RequestContext()
 	if r.Metadata != nil {
 		x, err := ctx.ExecuteTemplate(r.Metadata)
 		if err != nil {
-			return ctx, nil, errors.WrapPathf(err, "metadata", "failed to set metadata")
+			return reflect.Value{}, nil, nil, errors.WrapPathf(err, "metadata", "failed to set metadata")
 		}
 		md, err := reflectutil.ConvertStringsMap(reflect.ValueOf(x))
 		if err != nil {
-			return nil, nil, errors.WrapPathf(err, "metadata", "failed to set metadata")
+			return reflect.Value{}, nil, nil, errors.WrapPathf(err, "metadata", "failed to set metadata")
 		}
 
 		pairs := []string{}
@@ -108,55 +210,12 @@ func (r *Request) Invoke(ctx *context.Context) (*context.Context, interface{}, e
 		reqCtx = metadata.AppendToOutgoingContext(reqCtx, pairs...)
 	}
 
-	var in []reflect.Value
-	for i := 0; i < method.Type().NumIn(); i++ {
-		switch i {
-		case 0:
-			in = append(in, reflect.ValueOf(reqCtx))
-		case 1:
-			req := reflect.New(method.Type().In(i).Elem()).Interface()
-			if err := buildRequestMsg(ctx, req, r.Message); err != nil {
-				return ctx, nil, errors.WrapPathf(err, "message", "failed to build request message")
-			}
-
-			ctx = ctx.WithRequest(req)
-			reqMD, _ := metadata.FromOutgoingContext(reqCtx)
-			if b, err := yaml.Marshal(Request{
-				Method:   r.Method,
-				Metadata: reqMD,
-				Message:  req,
-			}); err == nil {
-				ctx.Reporter().Logf("request:\n%s", r.addIndent(string(b), indentNum))
-			} else {
-				ctx.Reporter().Logf("failed to dump request:\n%s", err)
-			}
-
-			in = append(in, reflect.ValueOf(req))
-		}
-	}
-
-	var header, trailer metadata.MD
-	in = append(in,
-		reflect.ValueOf(grpc.Header(&header)),
-		reflect.ValueOf(grpc.Trailer(&trailer)),
-	)
-
-	rvalues := method.Call(in)
-	message := rvalues[0].Interface()
-	resp := response{
-		Header:  header,
-		Trailer: trailer,
-		Message: message,
-		rvalues: rvalues,
-	}
-	ctx = ctx.WithResponse(message)
-	if b, err := yaml.Marshal(resp); err == nil {
-		ctx.Reporter().Logf("response:\n%s", r.addIndent(string(b), indentNum))
-	} else {
-		ctx.Reporter().Logf("failed to dump response:\n%s", err)
+	req := reflect.New(method.Type().In(1).Elem()).Interface()
+	if err := buildRequestMsg(ctx, req, r.Message); err != nil {
+		return reflect.Value{}, nil, nil, errors.WrapPathf(err, "message", "failed to build request message")
 	}
 
-	return ctx, resp, nil
+	return method, reqCtx, req, nil
 }
 
 func validateMethod(method reflect.Value) error {
@@ -214,3 +273,12 @@ func buildRequestMsg(ctx *context.Context, req interface{}, src interface{}) err
 	}
 	return nil
 }
+
+// StatusCode implements protocol.StatusCoder interface.
+func (r response) StatusCode() string {
+	_, sts, err := extract(r)
+	if err != nil {
+		return ""
+	}
+	return sts.Code().String()
+}
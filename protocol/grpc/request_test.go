@@ -235,6 +235,53 @@ ok  	test.yaml	0.000s
 	}
 }
 
+func TestRequest_BuildRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	// no expected calls because the request is not sent
+	client := test.NewMockTestClient(ctrl)
+
+	r := &Request{
+		Client: "{{vars.client}}",
+		Method: "Echo",
+		Metadata: map[string]string{
+			"version": "{{vars.version}}",
+		},
+		Message: yaml.MapSlice{
+			yaml.MapItem{Key: "messageId", Value: "1"},
+			yaml.MapItem{Key: "messageBody", Value: "hello"},
+		},
+	}
+	ctx := context.FromT(t).WithVars(map[string]interface{}{
+		"client":  client,
+		"version": "1.0.0",
+	})
+	got, err := r.BuildRequest(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	b, err := yaml.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := strings.TrimPrefix(`
+method: Echo
+metadata:
+  version:
+  - 1.0.0
+message:
+  messageId: "1"
+  messageBody: hello
+`, "\n")
+	if diff := cmp.Diff(expect, string(b)); diff != "" {
+		t.Errorf("differs (-want +got):\n%s", diff)
+	}
+
+	if _, err := (&Request{Method: "Echo"}).BuildRequest(ctx); err == nil {
+		t.Fatal("expected error but no error")
+	}
+}
+
 func TestValidateMethod(t *testing.T) {
 	t.Run("valid", func(t *testing.T) {
 		method := reflect.ValueOf(test.NewTestClient(nil)).MethodByName("Echo")
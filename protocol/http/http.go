@@ -2,6 +2,7 @@ package http
 
 import (
 	"bytes"
+	"reflect"
 
 	"github.com/goccy/go-yaml"
 	"github.com/zoncoen/scenarigo/protocol"
@@ -40,3 +41,13 @@ func (p *HTTP) UnmarshalExpect(b []byte) (protocol.AssertionBuilder, error) {
 	}
 	return &e, nil
 }
+
+// RequestType implements protocol.TypeProvider interface.
+func (p *HTTP) RequestType() reflect.Type {
+	return reflect.TypeOf(Request{})
+}
+
+// ExpectType implements protocol.TypeProvider interface.
+func (p *HTTP) ExpectType() reflect.Type {
+	return reflect.TypeOf(Expect{})
+}
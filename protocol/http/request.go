@@ -14,6 +14,7 @@ import (
 	"github.com/zoncoen/scenarigo/context"
 	"github.com/zoncoen/scenarigo/errors"
 	"github.com/zoncoen/scenarigo/internal/reflectutil"
+	"github.com/zoncoen/scenarigo/protocol"
 	"github.com/zoncoen/scenarigo/protocol/http/marshaler"
 	"github.com/zoncoen/scenarigo/protocol/http/unmarshaler"
 	"github.com/zoncoen/scenarigo/version"
@@ -64,14 +65,12 @@ func (r *Request) Invoke(ctx *context.Context) (*context.Context, interface{}, e
 	if err != nil {
 		return ctx, nil, err
 	}
+	if err := protocol.RunRequestHooks(ctx, req); err != nil {
+		return ctx, nil, errors.Errorf("failed to run OnRequest hook: %s", err)
+	}
 
 	ctx = ctx.WithRequest(reqBody)
-	if b, err := yaml.Marshal(Request{
-		Method: req.Method,
-		URL:    req.URL.String(),
-		Header: req.Header,
-		Body:   reqBody,
-	}); err == nil {
+	if b, err := yaml.Marshal(dumpRequest(req, reqBody)); err == nil {
 		ctx.Reporter().Logf("request:\n%s", r.addIndent(string(b), indentNum))
 	} else {
 		ctx.Reporter().Logf("failed to dump request:\n%s", err)
@@ -100,6 +99,11 @@ func (r *Request) Invoke(ctx *context.Context) (*context.Context, interface{}, e
 	if err != nil {
 		return ctx, nil, errors.Errorf("failed to read response body: %s", err)
 	}
+	// enable the hooks to read the decoded body
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	if err := protocol.RunResponseHooks(ctx, resp); err != nil {
+		return ctx, nil, errors.Errorf("failed to run OnResponse hook: %s", err)
+	}
 
 	rvalue := response{
 		Header: resp.Header,
@@ -122,6 +126,24 @@ func (r *Request) Invoke(ctx *context.Context) (*context.Context, interface{}, e
 	return ctx, rvalue, nil
 }
 
+// BuildRequest implements protocol.RequestBuilder interface.
+func (r *Request) BuildRequest(ctx *context.Context) (interface{}, error) {
+	req, reqBody, err := r.buildRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return dumpRequest(req, reqBody), nil
+}
+
+func dumpRequest(req *http.Request, body interface{}) *Request {
+	return &Request{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header,
+		Body:   body,
+	}
+}
+
 func (r *Request) buildClient(ctx *context.Context) (*http.Client, error) {
 	client := &http.Client{}
 	if r.Client != "" {
@@ -231,3 +253,8 @@ func (r *Request) buildRequest(ctx *context.Context) (*http.Request, interface{}
 
 	return req, body, nil
 }
+
+// StatusCode implements protocol.StatusCoder interface.
+func (r response) StatusCode() string {
+	return strings.SplitN(r.status, " ", 2)[0]
+}
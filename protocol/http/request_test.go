@@ -332,6 +332,46 @@ ok  	test.yaml	0.000s
 	})
 }
 
+func TestRequest_BuildRequest(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripper(func(req *http.Request) (*http.Response, error) {
+			t.Fatal("the request must not be sent")
+			return nil, nil
+		}),
+	}
+	r := &Request{
+		Client: "{{vars.client}}",
+		Method: http.MethodPost,
+		URL:    "{{vars.url}}/echo",
+		Query:  url.Values{"query": []string{"hello"}},
+		Body:   map[string]string{"message": "{{vars.message}}"},
+	}
+	ctx := context.FromT(t).WithVars(map[string]interface{}{
+		"client":  client,
+		"url":     "http://example.com",
+		"message": "hey",
+	})
+	got, err := r.BuildRequest(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expect := &Request{
+		Method: http.MethodPost,
+		URL:    "http://example.com/echo?query=hello",
+		Header: http.Header{
+			"User-Agent": []string{defaultUserAgent},
+		},
+		Body: map[string]string{"message": "hey"},
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("differs (-want +got):\n%s", diff)
+	}
+
+	if _, err := (&Request{URL: "{{vars.unknown}}"}).BuildRequest(ctx); err == nil {
+		t.Fatal("expected error but no error")
+	}
+}
+
 func TestRequest_buildRequest(t *testing.T) {
 	tests := map[string]struct {
 		req        *Request
@@ -2,6 +2,8 @@
 package protocol
 
 import (
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
 
@@ -39,6 +41,22 @@ func Get(name string) Protocol {
 	return p
 }
 
+// Protocols returns all registered protocols sorted by name.
+func Protocols() []Protocol {
+	m.Lock()
+	defer m.Unlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	ps := make([]Protocol, 0, len(names))
+	for _, name := range names {
+		ps = append(ps, registry[name])
+	}
+	return ps
+}
+
 // Protocol is the interface that creates Invoker and AssertionBuilder from YAML.
 type Protocol interface {
 	Name() string
@@ -51,7 +69,83 @@ type Invoker interface {
 	Invoke(*context.Context) (*context.Context, interface{}, error)
 }
 
+// RequestBuilder is the optional interface implemented by invokers which can build the request without sending it.
+// It is used by the dry-run mode, and the invokers which don't implement it are stubbed out.
+type RequestBuilder interface {
+	// BuildRequest executes the templates and returns the request to send.
+	BuildRequest(*context.Context) (interface{}, error)
+}
+
+// OnRequest is the optional interface implemented by plugins to modify the request before sending it
+// like adding tracing headers or signing.
+// The request is *http.Request for HTTP and *grpc.OutgoingRequest for gRPC.
+type OnRequest interface {
+	OnRequest(ctx *context.Context, req interface{}) error
+}
+
+// OnResponse is the optional interface implemented by plugins to inspect the response like recording metrics.
+// The response is *http.Response for HTTP and *grpc.IncomingResponse for gRPC.
+type OnResponse interface {
+	OnResponse(ctx *context.Context, resp interface{}) error
+}
+
+// RunRequestHooks calls the OnRequest hooks of the plugins in ctx.
+// The hook is the plugin which implements OnRequest or the function exported as "OnRequest" by the Go plugin.
+func RunRequestHooks(ctx *context.Context, req interface{}) error {
+	for _, h := range ctx.Plugins().Hooks("OnRequest") {
+		var err error
+		switch f := h.(type) {
+		case OnRequest:
+			err = f.OnRequest(ctx, req)
+		case func(*context.Context, interface{}) error:
+			err = f(ctx, req)
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunResponseHooks calls the OnResponse hooks of the plugins in ctx.
+// The hook is the plugin which implements OnResponse or the function exported as "OnResponse" by the Go plugin.
+func RunResponseHooks(ctx *context.Context, resp interface{}) error {
+	for _, h := range ctx.Plugins().Hooks("OnResponse") {
+		var err error
+		switch f := h.(type) {
+		case OnResponse:
+			err = f.OnResponse(ctx, resp)
+		case func(*context.Context, interface{}) error:
+			err = f(ctx, resp)
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // AssertionBuilder builds the assertion for the result of Invoke.
 type AssertionBuilder interface {
 	Build(*context.Context) (assert.Assertion, error)
 }
+
+// StatusCoder is the optional interface implemented by responses which have a status code.
+// It is used to decide whether to retry the request by the status code.
+type StatusCoder interface {
+	// StatusCode returns the status code of the response like "503" (HTTP) or "Unavailable" (gRPC).
+	StatusCode() string
+}
+
+// TypeProvider is the optional interface implemented by protocols which provide the Go types of request and expect.
+// It is used to generate the JSON Schema of scenario files.
+type TypeProvider interface {
+	// RequestType returns the type which the request YAML is decoded into.
+	RequestType() reflect.Type
+	// ExpectType returns the type which the expect YAML is decoded into.
+	ExpectType() reflect.Type
+}
@@ -38,6 +38,13 @@ func GenerateTestReport(r Reporter) (*TestReport, error) {
 				Result:   testResult(scenario),
 				Duration: TestDuration(scenario.getDuration()),
 			}
+			if reason, ok := scenario.getExpectedFailure(); ok {
+				scenarioReport.ExpectFailure = reason
+			}
+			if scenarioReport.Result == TestResultSkipped {
+				scenarioReport.SkipReason = scenario.getSkipReason()
+			}
+			scenarioReport.Retries = scenario.getRetries()
 			for _, step := range scenario.getChildren() {
 				step := step
 				logs := step.getLogs()
@@ -50,17 +57,90 @@ func GenerateTestReport(r Reporter) (*TestReport, error) {
 						Error: logs.errorLogs(),
 						Skip:  logs.skipLog(),
 					},
+					Retry:    step.getRetryStats(),
 					SubSteps: generateSubStepReports(step),
 				}
 				scenarioReport.Steps = append(scenarioReport.Steps, stepReport)
 			}
 			fileReport.Scenarios = append(fileReport.Scenarios, scenarioReport)
 		}
+		fileReport.PassRates = passRates(fileReport.Scenarios)
 		report.Files = append(report.Files, fileReport)
 	}
 	return report, nil
 }
 
+// MergeTestReports merges the test reports like the reports of shards into one report.
+// The merged report fails if any of the reports failed.
+func MergeTestReports(reports ...*TestReport) *TestReport {
+	merged := &TestReport{
+		Result: TestResultPassed,
+	}
+	for _, report := range reports {
+		if report == nil {
+			continue
+		}
+		if merged.Name == "" {
+			merged.Name = report.Name
+		}
+		if report.Result == TestResultFailed {
+			merged.Result = TestResultFailed
+		}
+		for _, file := range report.Files {
+			file := file
+			if len(file.Scenarios) > 0 {
+				scenarios := make([]ScenarioReport, len(file.Scenarios))
+				for i, scenario := range file.Scenarios {
+					// File is not unmarshaled from JSON
+					if scenario.File == "" {
+						scenario.File = file.Name
+					}
+					scenarios[i] = scenario
+				}
+				file.Scenarios = scenarios
+			}
+			merged.Files = append(merged.Files, file)
+		}
+	}
+	return merged
+}
+
+// passRates aggregates the results of the scenarios which run multiple times like "--count".
+// The skipped runs are not counted.
+func passRates(scenarios []ScenarioReport) []PassRate {
+	var (
+		names []string
+		rates = map[string]*PassRate{}
+		runs  = map[string]int{}
+	)
+	for _, scenario := range scenarios {
+		if runs[scenario.Name] == 0 {
+			names = append(names, scenario.Name)
+			rates[scenario.Name] = &PassRate{Name: scenario.Name}
+		}
+		runs[scenario.Name]++
+		rate := rates[scenario.Name]
+		switch scenario.Result {
+		case TestResultSkipped:
+		case TestResultPassed, TestResultExpectedFailure, TestResultFlaky:
+			rate.Runs++
+			rate.Passed++
+		default:
+			rate.Runs++
+		}
+	}
+	var result []PassRate
+	for _, name := range names {
+		rate := rates[name]
+		if runs[name] < 2 || rate.Runs == 0 {
+			continue
+		}
+		rate.Rate = float64(rate.Passed) / float64(rate.Runs)
+		result = append(result, *rate)
+	}
+	return result
+}
+
 func generateSubStepReports(r Reporter) []SubStepReport {
 	children := r.getChildren()
 	if len(children) == 0 {
@@ -79,6 +159,7 @@ func generateSubStepReports(r Reporter) []SubStepReport {
 				Error: logs.errorLogs(),
 				Skip:  logs.skipLog(),
 			},
+			Retry:    child.getRetryStats(),
 			SubSteps: generateSubStepReports(child),
 		}
 	}
@@ -86,12 +167,22 @@ func generateSubStepReports(r Reporter) []SubStepReport {
 }
 
 func testResult(r Reporter) TestResult {
+	_, expected := r.getExpectedFailure()
 	if r.Failed() {
+		if expected {
+			return TestResultExpectedFailure
+		}
 		return TestResultFailed
 	}
 	if r.Skipped() {
 		return TestResultSkipped
 	}
+	if expected {
+		return TestResultUnexpectedPass
+	}
+	if r.getRetries() > 0 {
+		return TestResultFlaky
+	}
 	return TestResultPassed
 }
 
@@ -119,6 +210,15 @@ type ScenarioFileReport struct {
 	Result    TestResult       `json:"result" xml:"-"`
 	Duration  TestDuration     `json:"duration" xml:"time,attr"`
 	Scenarios []ScenarioReport `json:"scenarios" xml:"testcase"`
+	PassRates []PassRate       `json:"passRates,omitempty" xml:"-"`
+}
+
+// PassRate represents the pass rate of a scenario which runs multiple times.
+type PassRate struct {
+	Name   string  `json:"name"`
+	Runs   int     `json:"runs"`
+	Passed int     `json:"passed"`
+	Rate   float64 `json:"rate"`
 }
 
 type xmlScenarioFileReport ScenarioFileReport
@@ -151,11 +251,14 @@ func (r ScenarioFileReport) MarshalXML(e *xml.Encoder, start xml.StartElement) e
 
 // ScenarioReport represents a result report of a test scenario.
 type ScenarioReport struct {
-	Name     string       `json:"name"`
-	File     string       `json:"-"`
-	Result   TestResult   `json:"result"`
-	Duration TestDuration `json:"duration"`
-	Steps    []StepReport `json:"steps"`
+	Name          string       `json:"name"`
+	File          string       `json:"-"`
+	Result        TestResult   `json:"result"`
+	ExpectFailure string       `json:"expectFailure,omitempty"`
+	SkipReason    SkipReason   `json:"skipReason,omitempty"`
+	Retries       int          `json:"retries,omitempty"`
+	Duration      TestDuration `json:"duration"`
+	Steps         []StepReport `json:"steps"`
 }
 
 type xmlScenarioReport struct {
@@ -211,6 +314,19 @@ func (r ScenarioReport) MarshalXML(e *xml.Encoder, start xml.StartElement) error
 				break
 			}
 		}
+	case TestResultExpectedFailure:
+		// JUnit format has no status for expected failures, so report them as skipped.
+		xr.Skipped = &xmlScenarioReportDetail{
+			Message: fmt.Sprintf("expected failure: %s", r.ExpectFailure),
+		}
+	case TestResultUnexpectedPass:
+		xr.SystemOut = &xmlCDATA{
+			CDATA: fmt.Sprintf("unexpected pass: the expectFailure marker (%s) can be removed", r.ExpectFailure),
+		}
+	case TestResultFlaky:
+		xr.SystemOut = &xmlCDATA{
+			CDATA: fmt.Sprintf("flaky: passed after %d retries", r.Retries),
+		}
 	default:
 	}
 	return e.EncodeElement(xr, start)
@@ -222,6 +338,7 @@ type StepReport struct {
 	Result   TestResult      `json:"result"`
 	Duration TestDuration    `json:"duration"`
 	Logs     ReportLogs      `json:"logs"`
+	Retry    *RetryStats     `json:"retry,omitempty"`
 	SubSteps []SubStepReport `json:"subSteps,omitempty"`
 }
 
@@ -237,9 +354,22 @@ type SubStepReport struct {
 	Result   TestResult      `json:"result"`
 	Duration TestDuration    `json:"duration"`
 	Logs     ReportLogs      `json:"logs"`
+	Retry    *RetryStats     `json:"retry,omitempty"`
 	SubSteps []SubStepReport `json:"subSteps,omitempty"`
 }
 
+// RetryStats represents the statistics of retries of a step.
+type RetryStats struct {
+	Attempts  int          `json:"attempts"`
+	TotalWait TestDuration `json:"totalWait"`
+}
+
+// SkipReason represents a machine-readable reason why a test was skipped.
+type SkipReason string
+
+// SkipReasonFailFast is the reason of the tests which were skipped or canceled by the fail fast.
+const SkipReasonFailFast SkipReason = "failFast"
+
 // TestResult represents a test result.
 type TestResult int
 
@@ -248,11 +378,17 @@ const (
 	TestResultPassed
 	TestResultFailed
 	TestResultSkipped
-
-	testResultUndefinedString = "undefined"
-	testResultPassedString    = "passed"
-	testResultFailedString    = "failed"
-	testResultSkippedString   = "skipped"
+	TestResultExpectedFailure
+	TestResultUnexpectedPass
+	TestResultFlaky
+
+	testResultUndefinedString       = "undefined"
+	testResultPassedString          = "passed"
+	testResultFailedString          = "failed"
+	testResultSkippedString         = "skipped"
+	testResultExpectedFailureString = "expectedFailure"
+	testResultUnexpectedPassString  = "unexpectedPass"
+	testResultFlakyString           = "flaky"
 )
 
 // String returns r as a string.
@@ -264,6 +400,12 @@ func (r TestResult) String() string {
 		return testResultFailedString
 	case TestResultSkipped:
 		return testResultSkippedString
+	case TestResultExpectedFailure:
+		return testResultExpectedFailureString
+	case TestResultUnexpectedPass:
+		return testResultUnexpectedPassString
+	case TestResultFlaky:
+		return testResultFlakyString
 	default:
 		return testResultUndefinedString
 	}
@@ -287,6 +429,12 @@ func (r *TestResult) UnmarshalJSON(b []byte) error {
 		*r = TestResultFailed
 	case testResultSkippedString:
 		*r = TestResultSkipped
+	case testResultExpectedFailureString:
+		*r = TestResultExpectedFailure
+	case testResultUnexpectedPassString:
+		*r = TestResultUnexpectedPass
+	case testResultFlakyString:
+		*r = TestResultFlaky
 	case testResultUndefinedString:
 		*r = TestResultUndefined
 	default:
@@ -313,6 +461,12 @@ func (r *TestResult) UnmarshalYAML(b []byte) error {
 		*r = TestResultFailed
 	case testResultSkippedString:
 		*r = TestResultSkipped
+	case testResultExpectedFailureString:
+		*r = TestResultExpectedFailure
+	case testResultUnexpectedPassString:
+		*r = TestResultUnexpectedPass
+	case testResultFlakyString:
+		*r = TestResultFlaky
 	case testResultUndefinedString:
 		*r = TestResultUndefined
 	default:
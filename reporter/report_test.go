@@ -52,6 +52,43 @@ func TestGenerateTestReport(t *testing.T) {
 					},
 				},
 			},
+			"retried step": {
+				f: func(r Reporter) {
+					r.Run("file1.yaml", func(r Reporter) {
+						r.Run("scenario1", func(r Reporter) {
+							r.Run("step1", func(r Reporter) {
+								r.RecordRetryStats(3, 2*time.Second)
+							})
+						})
+					})
+				},
+				expect: &TestReport{
+					Result: TestResultPassed,
+					Files: []ScenarioFileReport{
+						{
+							Name:   "file1.yaml",
+							Result: TestResultPassed,
+							Scenarios: []ScenarioReport{
+								{
+									Name:   "scenario1",
+									File:   "file1.yaml",
+									Result: TestResultPassed,
+									Steps: []StepReport{
+										{
+											Name:   "step1",
+											Result: TestResultPassed,
+											Retry: &RetryStats{
+												Attempts:  3,
+												TotalWait: TestDuration(2 * time.Second),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
 			"has sub steps (include)": {
 				f: func(r Reporter) {
 					r.Run("file1.yaml", func(r Reporter) {
@@ -215,6 +252,249 @@ func TestGenerateTestReport(t *testing.T) {
 			})
 		}
 	})
+	t.Run("expected failure", func(t *testing.T) {
+		tests := map[string]struct {
+			f      func(r Reporter)
+			expect *TestReport
+		}{
+			"failed as expected": {
+				f: func(r Reporter) {
+					r.Run("file1.yaml", func(r Reporter) {
+						r.Run("scenario1", func(r Reporter) {
+							r.ExpectFailure("ISSUE-1")
+							r.Run("step1", func(r Reporter) {
+								r.Fatal("fatal")
+							})
+						})
+					})
+				},
+				expect: &TestReport{
+					Result: TestResultPassed,
+					Files: []ScenarioFileReport{
+						{
+							Name:   "file1.yaml",
+							Result: TestResultPassed,
+							Scenarios: []ScenarioReport{
+								{
+									Name:          "scenario1",
+									File:          "file1.yaml",
+									Result:        TestResultExpectedFailure,
+									ExpectFailure: "ISSUE-1",
+									Steps: []StepReport{
+										{
+											Name:   "step1",
+											Result: TestResultFailed,
+											Logs: ReportLogs{
+												Error: []string{
+													"fatal",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"passed unexpectedly": {
+				f: func(r Reporter) {
+					r.Run("file1.yaml", func(r Reporter) {
+						r.Run("scenario1", func(r Reporter) {
+							r.ExpectFailure("ISSUE-1")
+							r.Run("step1", func(r Reporter) {})
+						})
+					})
+				},
+				expect: &TestReport{
+					Result: TestResultPassed,
+					Files: []ScenarioFileReport{
+						{
+							Name:   "file1.yaml",
+							Result: TestResultPassed,
+							Scenarios: []ScenarioReport{
+								{
+									Name:          "scenario1",
+									File:          "file1.yaml",
+									Result:        TestResultUnexpectedPass,
+									ExpectFailure: "ISSUE-1",
+									Steps: []StepReport{
+										{
+											Name:   "step1",
+											Result: TestResultPassed,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				r := run(func(r Reporter) {
+					r.(*reporter).durationMeasurer = &fixedDurationMeasurer{}
+					test.f(r)
+				}, WithWriter(&nopWriter{}))
+				checkReport(t, r, test.expect)
+			})
+		}
+	})
+	t.Run("retry", func(t *testing.T) {
+		tests := map[string]struct {
+			f      func(r Reporter)
+			expect *TestReport
+		}{
+			"flaky": {
+				f: func(r Reporter) {
+					r.Run("file1.yaml", func(r Reporter) {
+						r.Run("scenario1", func(r Reporter) {
+							r.EnableRetry()
+							r.Run("step1", func(r Reporter) {
+								r.Fatal("fatal")
+							})
+							r.Retry()
+							r.Run("step1", func(r Reporter) {})
+						})
+					})
+				},
+				expect: &TestReport{
+					Result: TestResultPassed,
+					Files: []ScenarioFileReport{
+						{
+							Name:   "file1.yaml",
+							Result: TestResultPassed,
+							Scenarios: []ScenarioReport{
+								{
+									Name:    "scenario1",
+									File:    "file1.yaml",
+									Result:  TestResultFlaky,
+									Retries: 1,
+									Steps: []StepReport{
+										{
+											Name:   "step1",
+											Result: TestResultPassed,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"failed": {
+				f: func(r Reporter) {
+					r.Run("file1.yaml", func(r Reporter) {
+						r.Run("scenario1", func(r Reporter) {
+							r.EnableRetry()
+							r.Run("step1", func(r Reporter) {
+								r.Fatal("fatal")
+							})
+							r.Retry()
+							r.Run("step1", func(r Reporter) {
+								r.Fatal("fatal")
+							})
+						})
+					})
+				},
+				expect: &TestReport{
+					Result: TestResultFailed,
+					Files: []ScenarioFileReport{
+						{
+							Name:   "file1.yaml",
+							Result: TestResultFailed,
+							Scenarios: []ScenarioReport{
+								{
+									Name:    "scenario1",
+									File:    "file1.yaml",
+									Result:  TestResultFailed,
+									Retries: 1,
+									Steps: []StepReport{
+										{
+											Name:   "step1",
+											Result: TestResultFailed,
+											Logs: ReportLogs{
+												Error: []string{
+													"fatal",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				r := run(func(r Reporter) {
+					r.(*reporter).durationMeasurer = &fixedDurationMeasurer{}
+					test.f(r)
+				}, WithWriter(&nopWriter{}))
+				checkReport(t, r, test.expect)
+			})
+		}
+	})
+	t.Run("count", func(t *testing.T) {
+		r := run(func(r Reporter) {
+			r.(*reporter).durationMeasurer = &fixedDurationMeasurer{}
+			r.Run("file1.yaml", func(r Reporter) {
+				for i := 0; i < 3; i++ {
+					i := i
+					r.Run("scenario1", func(r Reporter) {
+						if i == 1 {
+							r.Fail()
+						}
+					})
+				}
+				r.Run("scenario2", func(r Reporter) {})
+			})
+		}, WithWriter(&nopWriter{}))
+		checkReport(t, r, &TestReport{
+			Result: TestResultFailed,
+			Files: []ScenarioFileReport{
+				{
+					Name:   "file1.yaml",
+					Result: TestResultFailed,
+					Scenarios: []ScenarioReport{
+						{
+							Name:   "scenario1",
+							File:   "file1.yaml",
+							Result: TestResultPassed,
+						},
+						{
+							Name:   "scenario1",
+							File:   "file1.yaml",
+							Result: TestResultFailed,
+						},
+						{
+							Name:   "scenario1",
+							File:   "file1.yaml",
+							Result: TestResultPassed,
+						},
+						{
+							Name:   "scenario2",
+							File:   "file1.yaml",
+							Result: TestResultPassed,
+						},
+					},
+					PassRates: []PassRate{
+						{
+							Name:   "scenario1",
+							Runs:   3,
+							Passed: 2,
+							Rate:   2.0 / 3,
+						},
+					},
+				},
+			},
+		})
+	})
 	t.Run("error", func(t *testing.T) {
 		t.Run("nil", func(t *testing.T) {
 			if _, err := GenerateTestReport(nil); err == nil {
@@ -265,6 +545,18 @@ func TestTestResult(t *testing.T) {
 			result: TestResultSkipped,
 			expect: "skipped",
 		},
+		{
+			result: TestResultExpectedFailure,
+			expect: "expectedFailure",
+		},
+		{
+			result: TestResultUnexpectedPass,
+			expect: "unexpectedPass",
+		},
+		{
+			result: TestResultFlaky,
+			expect: "flaky",
+		},
 	}
 	for _, test := range tests {
 		test := test
@@ -433,6 +725,24 @@ func TestTestReport_MarshalXML(t *testing.T) {
 									},
 								},
 							},
+							{
+								Name:          "expected failure scenario",
+								File:          "file1.yaml",
+								Result:        TestResultExpectedFailure,
+								ExpectFailure: "ISSUE-1",
+							},
+							{
+								Name:          "unexpected pass scenario",
+								File:          "file1.yaml",
+								Result:        TestResultUnexpectedPass,
+								ExpectFailure: "ISSUE-2",
+							},
+							{
+								Name:    "flaky scenario",
+								File:    "file1.yaml",
+								Result:  TestResultFlaky,
+								Retries: 2,
+							},
 						},
 					},
 				},
@@ -466,3 +776,99 @@ func TestTestReport_MarshalXML(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeTestReports(t *testing.T) {
+	tests := map[string]struct {
+		reports []*TestReport
+		expect  *TestReport
+	}{
+		"empty": {
+			expect: &TestReport{
+				Result: TestResultPassed,
+			},
+		},
+		"passed": {
+			reports: []*TestReport{
+				{
+					Name:   "shard1",
+					Result: TestResultPassed,
+					Files: []ScenarioFileReport{
+						{
+							Name:   "a.yaml",
+							Result: TestResultPassed,
+							Scenarios: []ScenarioReport{
+								{Name: "a", Result: TestResultPassed},
+							},
+						},
+					},
+				},
+				{
+					Name:   "shard2",
+					Result: TestResultPassed,
+					Files: []ScenarioFileReport{
+						{
+							Name:   "b.yaml",
+							Result: TestResultPassed,
+						},
+					},
+				},
+			},
+			expect: &TestReport{
+				Name:   "shard1",
+				Result: TestResultPassed,
+				Files: []ScenarioFileReport{
+					{
+						Name:   "a.yaml",
+						Result: TestResultPassed,
+						Scenarios: []ScenarioReport{
+							{Name: "a", File: "a.yaml", Result: TestResultPassed},
+						},
+					},
+					{
+						Name:   "b.yaml",
+						Result: TestResultPassed,
+					},
+				},
+			},
+		},
+		"failed": {
+			reports: []*TestReport{
+				{
+					Result: TestResultPassed,
+				},
+				{
+					Result: TestResultFailed,
+					Files: []ScenarioFileReport{
+						{
+							Name:   "b.yaml",
+							Result: TestResultFailed,
+							Scenarios: []ScenarioReport{
+								{Name: "b", Result: TestResultFailed},
+							},
+						},
+					},
+				},
+			},
+			expect: &TestReport{
+				Result: TestResultFailed,
+				Files: []ScenarioFileReport{
+					{
+						Name:   "b.yaml",
+						Result: TestResultFailed,
+						Scenarios: []ScenarioReport{
+							{Name: "b", File: "b.yaml", Result: TestResultFailed},
+						},
+					},
+				},
+			},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(test.expect, MergeTestReports(test.reports...)); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -33,6 +33,11 @@ type Reporter interface {
 	Skipf(format string, args ...interface{})
 	SkipNow()
 	Skipped() bool
+	SkipWithReason(reason SkipReason, args ...interface{})
+	ExpectFailure(reason string)
+	RecordRetryStats(attempts int, totalWait time.Duration)
+	EnableRetry()
+	Retry()
 	Parallel()
 	Run(name string, f func(r Reporter)) bool
 
@@ -41,6 +46,10 @@ type Reporter interface {
 	getDuration() time.Duration
 	getLogs() *logRecorder
 	getChildren() []Reporter
+	getExpectedFailure() (string, bool)
+	getSkipReason() SkipReason
+	getRetryStats() *RetryStats
+	getRetries() int
 	isRoot() bool
 }
 
@@ -71,6 +80,11 @@ type reporter struct {
 	failed           int32
 	skipped          int32
 	isParallel       bool
+	expectFailure    *string
+	skipReason       SkipReason
+	retryStats       *RetryStats
+	retryable        bool
+	retries          int
 	logs             *logRecorder
 	durationMeasurer testDurationMeasurer
 	children         []*reporter
@@ -94,13 +108,20 @@ func (r *reporter) Name() string {
 }
 
 // Fail marks the function as having failed but continues execution.
+// The failure is not propagated to the parent if the test is expected to fail.
+// If the test is retryable, the failure is propagated after the test finishes.
 func (r *reporter) Fail() {
-	if r.parent != nil {
+	if r.parent != nil && r.propagatesFailure() && !r.isRetryable() {
 		r.parent.Fail()
 	}
 	atomic.StoreInt32(&r.failed, 1)
 }
 
+func (r *reporter) propagatesFailure() bool {
+	_, expected := r.getExpectedFailure()
+	return !expected
+}
+
 // Failed reports whether the function has failed.
 func (r *reporter) Failed() bool {
 	return atomic.LoadInt32(&r.failed) > 0
@@ -176,6 +197,92 @@ func (r *reporter) SkipNow() {
 	runtime.Goexit()
 }
 
+// SkipWithReason is equivalent to Skip but also records the reason to report it.
+func (r *reporter) SkipWithReason(reason SkipReason, args ...interface{}) {
+	r.m.Lock()
+	r.skipReason = reason
+	r.m.Unlock()
+	r.Skip(args...)
+}
+
+// ExpectFailure marks the test as expected to fail with the reason.
+// The failure of the test doesn't make its parent fail,
+// and the test is reported as an unexpected pass if it succeeds.
+func (r *reporter) ExpectFailure(reason string) {
+	r.m.Lock()
+	r.expectFailure = &reason
+	r.m.Unlock()
+}
+
+// RecordRetryStats records the statistics of retries to report them.
+func (r *reporter) RecordRetryStats(attempts int, totalWait time.Duration) {
+	r.m.Lock()
+	r.retryStats = &RetryStats{
+		Attempts:  attempts,
+		TotalWait: TestDuration(totalWait),
+	}
+	r.m.Unlock()
+}
+
+// EnableRetry enables to retry the test by Retry.
+// The failure of the test is propagated to its parent after the test finishes
+// because it may pass by retrying.
+func (r *reporter) EnableRetry() {
+	r.m.Lock()
+	r.retryable = true
+	r.m.Unlock()
+}
+
+// Retry discards the results of subtests and the failure of the test to retry it.
+// The failed subtests are recorded in the log.
+// The test is reported as flaky if it passes after retries.
+func (r *reporter) Retry() {
+	if !r.isRetryable() {
+		panic("reporter: Reporter.Retry called without EnableRetry")
+	}
+	r.m.Lock()
+	r.retries++
+	for _, child := range r.children {
+		if child.Failed() {
+			r.logs.log(fmt.Sprintf("attempt %d failed at %s", r.retries, child.name))
+		}
+	}
+	r.children = nil
+	r.m.Unlock()
+	atomic.StoreInt32(&r.failed, 0)
+}
+
+func (r *reporter) isRetryable() bool {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return r.retryable
+}
+
+// failedUnexpectedly reports whether the test has failed
+// and neither it nor its ancestors are expected to fail.
+func (r *reporter) failedUnexpectedly() bool {
+	if !r.Failed() {
+		return false
+	}
+	for p := r; p != nil; p = p.parent {
+		if _, expected := p.getExpectedFailure(); expected {
+			return false
+		}
+	}
+	return true
+}
+
+// passedUnexpectedly reports whether the test is expected to fail but has succeeded.
+func (r *reporter) passedUnexpectedly() bool {
+	_, expected := r.getExpectedFailure()
+	return expected && !r.Failed() && !r.Skipped()
+}
+
+// flaky reports whether the test has passed after retries.
+func (r *reporter) flaky() bool {
+	return r.getRetries() > 0 && !r.Failed() && !r.Skipped()
+}
+
 // Parallel signals that this test is to be run in parallel with (and only with)
 // other parallel tests.
 func (r *reporter) Parallel() {
@@ -299,6 +406,11 @@ func (r *reporter) run(f func(r Reporter)) {
 			r.context.release()
 		}
 
+		// Propagate the failure held for retrying.
+		if r.Failed() && r.parent != nil && r.propagatesFailure() && r.isRetryable() {
+			r.parent.Fail()
+		}
+
 		r.done <- true
 	}()
 
@@ -317,16 +429,27 @@ func print(r *reporter) {
 
 func collectOutput(r *reporter) []string {
 	var results []string
-	if r.Failed() || r.context.verbose {
+	if r.failedUnexpectedly() || r.passedUnexpectedly() || r.flaky() || r.context.verbose {
 		prefix := strings.Repeat("    ", r.depth-1)
+		_, expected := r.getExpectedFailure()
 		status := "PASS"
 		c := r.passColor()
-		if r.Failed() {
+		switch {
+		case r.Failed() && expected:
+			status = "XFAIL"
+			c = r.skipColor()
+		case r.Failed():
 			status = "FAIL"
 			c = r.failColor()
-		} else if r.Skipped() {
+		case r.Skipped():
 			status = "SKIP"
 			c = r.skipColor()
+		case expected:
+			status = "XPASS"
+			c = r.skipColor()
+		case r.flaky():
+			status = "FLAKY"
+			c = r.skipColor()
 		}
 		results = []string{
 			c.Sprintf("%s--- %s: %s (%.2fs)", prefix, status, r.goTestName, r.durationMeasurer.getDuration().Seconds()),
@@ -392,6 +515,33 @@ func (r *reporter) getChildren() []Reporter {
 	return children
 }
 
+func (r *reporter) getExpectedFailure() (string, bool) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if r.expectFailure == nil {
+		return "", false
+	}
+	return *r.expectFailure, true
+}
+
+func (r *reporter) getSkipReason() SkipReason {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return r.skipReason
+}
+
+func (r *reporter) getRetryStats() *RetryStats {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return r.retryStats
+}
+
+func (r *reporter) getRetries() int {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return r.retries
+}
+
 func (r *reporter) passColor() *color.Color {
 	if r.context.noColor {
 		return color.New()
@@ -32,6 +32,10 @@ type testReporter struct {
 	logs             *logRecorder
 	durationMeasurer testDurationMeasurer
 	root             bool
+	expectFailure    *string
+	skipReason       SkipReason
+	retryStats       *RetryStats
+	retries          int
 	children         []Reporter
 	mu               sync.Mutex
 }
@@ -142,6 +146,45 @@ func (r *testReporter) Skipf(format string, args ...interface{}) {
 	r.SkipNow()
 }
 
+// SkipWithReason is equivalent to Skip but also records the reason to report it.
+func (r *testReporter) SkipWithReason(reason SkipReason, args ...interface{}) {
+	r.mu.Lock()
+	r.skipReason = reason
+	r.mu.Unlock()
+	r.Skip(args...)
+}
+
+// ExpectFailure marks the test as expected to fail with the reason.
+// NOTE: testing.T doesn't support expected failures, so the failure is reported as is.
+func (r *testReporter) ExpectFailure(reason string) {
+	r.mu.Lock()
+	r.expectFailure = &reason
+	r.mu.Unlock()
+}
+
+// RecordRetryStats records the statistics of retries to report them.
+func (r *testReporter) RecordRetryStats(attempts int, totalWait time.Duration) {
+	r.mu.Lock()
+	r.retryStats = &RetryStats{
+		Attempts:  attempts,
+		TotalWait: TestDuration(totalWait),
+	}
+	r.mu.Unlock()
+}
+
+// EnableRetry enables to retry the test by Retry.
+// NOTE: testing.T can't hold failures, so it does nothing.
+func (r *testReporter) EnableRetry() {}
+
+// Retry discards the results of subtests to retry the test.
+// NOTE: testing.T can't discard failures, so the test remains failed if it has failed.
+func (r *testReporter) Retry() {
+	r.mu.Lock()
+	r.retries++
+	r.children = nil
+	r.mu.Unlock()
+}
+
 // Parallel signals that this test is to be run in parallel with (and only with)
 // other parallel tests.
 func (r *testReporter) Parallel() {
@@ -186,6 +229,33 @@ func (r *testReporter) getChildren() []Reporter {
 	return r.children
 }
 
+func (r *testReporter) getExpectedFailure() (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.expectFailure == nil {
+		return "", false
+	}
+	return *r.expectFailure, true
+}
+
+func (r *testReporter) getSkipReason() SkipReason {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.skipReason
+}
+
+func (r *testReporter) getRetryStats() *RetryStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.retryStats
+}
+
+func (r *testReporter) getRetries() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.retries
+}
+
 func (r *testReporter) isRoot() bool {
 	return r.root
 }
@@ -0,0 +1,67 @@
+package scenarigo
+
+import (
+	"strings"
+
+	"github.com/zoncoen/scenarigo/assert"
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/protocol"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+// statusConditionProtocols is the protocols of the responses which the status conditions of the retry policy match.
+var statusConditionProtocols = map[string]string{
+	schema.RetryOnStatusPrefix: "http",
+	schema.RetryOnGRPCPrefix:   "grpc",
+}
+
+// shouldRetry reports whether the failed attempt should be retried by p.
+// kind is the kind of the failure (schema.RetryOnRequestError or schema.RetryOnAssertionError),
+// and resp is the response of the attempt sent by the protocol protocolName.
+// The status conditions match only the responses of their protocols, for example, "grpc:" doesn't match HTTP responses.
+// If no conditions are specified, all failures are retried.
+func shouldRetry(p *schema.RetryPolicy, kind, protocolName string, resp interface{}) bool {
+	if p == nil || len(p.On) == 0 {
+		return true
+	}
+	for _, c := range p.On {
+		if c == kind {
+			return true
+		}
+		for prefix, name := range statusConditionProtocols {
+			if !strings.HasPrefix(c, prefix) || !strings.EqualFold(protocolName, name) {
+				continue
+			}
+			if sc, ok := resp.(protocol.StatusCoder); ok && equalStatusCode(sc.StatusCode(), strings.TrimPrefix(c, prefix)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// equalStatusCode compares the status codes ignoring case and underscores to accept both "UNAVAILABLE" and "Unavailable".
+func equalStatusCode(a, b string) bool {
+	return strings.EqualFold(strings.ReplaceAll(a, "_", ""), strings.ReplaceAll(b, "_", ""))
+}
+
+// satisfied reports whether the "until" condition of p holds in ctx.
+// The condition is satisfied if the executed value is true,
+// or the value is an assertion for the response which succeeds.
+// If p has no condition, satisfied always returns true.
+func satisfied(ctx *context.Context, p *schema.RetryPolicy) (bool, error) {
+	if p == nil || p.Until == nil {
+		return true, nil
+	}
+	x, err := ctx.ExecuteTemplate(p.Until)
+	if err != nil {
+		return false, err
+	}
+	if b, ok := x.(bool); ok {
+		return b, nil
+	}
+	if err := assert.Build(x).Assert(ctx.Response()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
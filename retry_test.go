@@ -0,0 +1,146 @@
+package scenarigo
+
+import (
+	"testing"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+type statusCoder string
+
+func (c statusCoder) StatusCode() string { return string(c) }
+
+func TestShouldRetry(t *testing.T) {
+	tests := map[string]struct {
+		policy   *schema.RetryPolicy
+		kind     string
+		protocol string
+		resp     interface{}
+		expect   bool
+	}{
+		"nil": {
+			kind:   schema.RetryOnAssertionError,
+			expect: true,
+		},
+		"no conditions": {
+			policy: &schema.RetryPolicy{},
+			kind:   schema.RetryOnAssertionError,
+			expect: true,
+		},
+		"request error": {
+			policy: &schema.RetryPolicy{On: []string{schema.RetryOnRequestError}},
+			kind:   schema.RetryOnRequestError,
+			expect: true,
+		},
+		"assertion error": {
+			policy:   &schema.RetryPolicy{On: []string{schema.RetryOnRequestError}},
+			kind:     schema.RetryOnAssertionError,
+			protocol: "http",
+			resp:     statusCoder("500"),
+			expect:   false,
+		},
+		"status": {
+			policy:   &schema.RetryPolicy{On: []string{"status:503"}},
+			kind:     schema.RetryOnAssertionError,
+			protocol: "http",
+			resp:     statusCoder("503"),
+			expect:   true,
+		},
+		"status mismatch": {
+			policy:   &schema.RetryPolicy{On: []string{"status:503"}},
+			kind:     schema.RetryOnAssertionError,
+			protocol: "http",
+			resp:     statusCoder("500"),
+			expect:   false,
+		},
+		"gRPC status": {
+			policy:   &schema.RetryPolicy{On: []string{"grpc:DEADLINE_EXCEEDED"}},
+			kind:     schema.RetryOnAssertionError,
+			protocol: "grpc",
+			resp:     statusCoder("DeadlineExceeded"),
+			expect:   true,
+		},
+		"no status": {
+			policy:   &schema.RetryPolicy{On: []string{"grpc:UNAVAILABLE"}},
+			kind:     schema.RetryOnAssertionError,
+			protocol: "grpc",
+			resp:     struct{}{},
+			expect:   false,
+		},
+		"status of gRPC response": {
+			policy:   &schema.RetryPolicy{On: []string{"status:503"}},
+			kind:     schema.RetryOnAssertionError,
+			protocol: "grpc",
+			resp:     statusCoder("503"),
+			expect:   false,
+		},
+		"gRPC status of HTTP response": {
+			policy:   &schema.RetryPolicy{On: []string{"grpc:UNAVAILABLE"}},
+			kind:     schema.RetryOnAssertionError,
+			protocol: "http",
+			resp:     statusCoder("Unavailable"),
+			expect:   false,
+		},
+		"no protocol": {
+			policy: &schema.RetryPolicy{On: []string{"status:503"}},
+			kind:   schema.RetryOnAssertionError,
+			resp:   statusCoder("503"),
+			expect: false,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			if got := shouldRetry(test.policy, test.kind, test.protocol, test.resp); got != test.expect {
+				t.Errorf("expect %t but got %t", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestSatisfied(t *testing.T) {
+	tests := map[string]struct {
+		policy *schema.RetryPolicy
+		resp   interface{}
+		expect bool
+	}{
+		"nil": {
+			expect: true,
+		},
+		"no condition": {
+			policy: &schema.RetryPolicy{},
+			expect: true,
+		},
+		"bool": {
+			policy: &schema.RetryPolicy{Until: "{{response.done}}"},
+			resp:   map[string]interface{}{"done": true},
+			expect: true,
+		},
+		"false": {
+			policy: &schema.RetryPolicy{Until: "{{response.done}}"},
+			resp:   map[string]interface{}{"done": false},
+			expect: false,
+		},
+		"assertion": {
+			policy: &schema.RetryPolicy{Until: map[string]interface{}{"status": "done"}},
+			resp:   map[string]interface{}{"status": "done"},
+			expect: true,
+		},
+		"assertion failed": {
+			policy: &schema.RetryPolicy{Until: map[string]interface{}{"status": "done"}},
+			resp:   map[string]interface{}{"status": "pending"},
+			expect: false,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			ctx := context.FromT(t).WithResponse(test.resp)
+			got, _ := satisfied(ctx, test.policy)
+			if got != test.expect {
+				t.Errorf("expect %t but got %t", test.expect, got)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package scenarigo
 
 import (
+	gocontext "context"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
@@ -8,7 +9,9 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/pkg/errors"
@@ -25,16 +28,45 @@ import (
 // Runner represents a test runner.
 type Runner struct {
 	pluginDir       *string
+	scenarioPaths   []string
 	scenarioFiles   []string
 	scenarioReaders []io.Reader
 	enabledColor    bool
 	rootDir         string
 	reportConfig    schema.ReportConfig
+	tags            []string
+	excludeTags     []string
+	runPattern      *regexp.Regexp
+	rerunFailed     map[string]map[string]bool
+	stepLibrary     schema.StepLibrary
+	vars            map[string]interface{}
+	env             map[string]string
+	profiles        map[string]schema.ProfileConfig
+	failFast        bool
+	dryRun          bool
+	shuffle         bool
+	shuffleSeed     int64
+	count           int
+	shardIndex      int
+	shardTotal      int
+	shardDurations  map[string]time.Duration
+	keptVars        *keptVars
+	plugins         map[string]string
+	setupFiles      []string
+	teardownFiles   []string
+
+	// the options and the files loaded by them to rebuild the runner in the watch mode
+	opts             []func(*Runner) error
+	reload           func() (*Runner, error)
+	configFile       string
+	stepLibraryPaths []string
+	varsFiles        []string
+	envFiles         []string
 }
 
 // NewRunner returns a new test runner.
 func NewRunner(opts ...func(*Runner) error) (*Runner, error) {
-	r := &Runner{}
+	r := &Runner{opts: opts}
 	r.enabledColor = !color.NoColor
 	for _, opt := range opts {
 		if err := opt(r); err != nil {
@@ -59,6 +91,7 @@ func WithConfig(config *schema.Config) func(*Runner) error {
 		}
 
 		r.rootDir = config.Root
+		r.configFile = config.Path
 		scenarios := make([]string, len(config.Scenarios))
 		for i, s := range config.Scenarios {
 			scenarios[i] = filepath.Join(r.rootDir, s)
@@ -70,6 +103,30 @@ func WithConfig(config *schema.Config) func(*Runner) error {
 			opts = append(opts, WithPluginDir(
 				filepath.Join(r.rootDir, config.PluginDirectory)))
 		}
+		if len(config.Plugins) > 0 {
+			opts = append(opts, WithPlugins(config.Plugins))
+		}
+		if len(config.StepLibraries) > 0 {
+			libs := make([]string, len(config.StepLibraries))
+			for i, l := range config.StepLibraries {
+				libs[i] = filepath.Join(r.rootDir, l)
+			}
+			opts = append(opts, WithStepLibraries(libs...))
+		}
+		if len(config.Setup) > 0 {
+			setup := make([]string, len(config.Setup))
+			for i, s := range config.Setup {
+				setup[i] = filepath.Join(r.rootDir, s)
+			}
+			opts = append(opts, WithSetup(setup...))
+		}
+		if len(config.Teardown) > 0 {
+			teardown := make([]string, len(config.Teardown))
+			for i, s := range config.Teardown {
+				teardown[i] = filepath.Join(r.rootDir, s)
+			}
+			opts = append(opts, WithTeardown(teardown...))
+		}
 		for _, opt := range opts {
 			if err := opt(r); err != nil {
 				return err
@@ -78,6 +135,21 @@ func WithConfig(config *schema.Config) func(*Runner) error {
 		if config.Output.Colored != nil {
 			r.enabledColor = *config.Output.Colored
 		}
+		r.vars = mergeVars(r.vars, config.Vars)
+		r.profiles = config.Profiles
+
+		opts = nil
+		for _, f := range config.VarsFiles {
+			opts = append(opts, WithVarsFile(filepath.Join(r.rootDir, f)))
+		}
+		for _, f := range config.EnvFiles {
+			opts = append(opts, WithEnvFile(filepath.Join(r.rootDir, f)))
+		}
+		for _, opt := range opts {
+			if err := opt(r); err != nil {
+				return err
+			}
+		}
 		r.reportConfig = config.Output.Report
 		return nil
 	}
@@ -97,6 +169,9 @@ func WithScenarios(paths ...string) func(*Runner) error {
 		if err != nil {
 			return fmt.Errorf("failed to find test scenarios: %w", err)
 		}
+		if len(paths) > 0 {
+			r.scenarioPaths = paths
+		}
 		r.scenarioFiles = files
 		return nil
 	}
@@ -114,6 +189,32 @@ func WithPluginDir(path string) func(*Runner) error {
 	}
 }
 
+// WithStepLibraries returns a option which loads step libraries from files and directories.
+func WithStepLibraries(paths ...string) func(*Runner) error {
+	return func(r *Runner) error {
+		r.stepLibraryPaths = paths
+		files, err := getAllFiles(paths...)
+		if err != nil {
+			return fmt.Errorf("failed to find step libraries: %w", err)
+		}
+		lib, err := schema.LoadStepLibrary(files...)
+		if err != nil {
+			return fmt.Errorf("failed to load step libraries: %w", err)
+		}
+		r.stepLibrary = lib
+		return nil
+	}
+}
+
+// WithPlugins returns a option which loads the plugins for all scenarios.
+// The paths are relative to the plugin directory like the plugins of scenarios.
+func WithPlugins(plugins map[string]string) func(*Runner) error {
+	return func(r *Runner) error {
+		r.plugins = plugins
+		return nil
+	}
+}
+
 // WithScenariosFromReader returns a option which sets readers to read scenario contents.
 func WithScenariosFromReader(readers ...io.Reader) func(*Runner) error {
 	return func(r *Runner) error {
@@ -135,6 +236,25 @@ func WithOptionsFromEnv(isEnv bool) func(*Runner) error {
 	}
 }
 
+// WithFailFast returns a option which stops running scenarios after the first failure.
+// The running scenarios are canceled via the request context, and the remaining scenarios are skipped.
+// The failures of the scenarios which are expected to fail don't stop running.
+func WithFailFast(failFast bool) func(*Runner) error {
+	return func(r *Runner) error {
+		r.failFast = failFast
+		return nil
+	}
+}
+
+// WithDryRun returns a option which renders the requests of the steps without sending them.
+// The steps which need a response, like plugin steps, are skipped.
+func WithDryRun(dryRun bool) func(*Runner) error {
+	return func(r *Runner) error {
+		r.dryRun = dryRun
+		return nil
+	}
+}
+
 var yamlPattern = regexp.MustCompile(`(?i)\.ya?ml$`)
 
 func looksLikeYAML(path string) bool {
@@ -179,53 +299,241 @@ func (r *Runner) setEnabledColor(envColor string) {
 }
 
 // ScenarioFiles returns all scenario file paths.
+// If the filter options are set, it returns only the files which contain scenarios to run.
+// If the shard option is set, it returns only the files of the shard.
 func (r *Runner) ScenarioFiles() []string {
-	return r.scenarioFiles
+	return r.shardFiles(r.filterFiles(r.scenarioFiles))
+}
+
+// PluginPaths returns the paths of the plugins referenced by the configuration and the scenario files
+// including the included files and the setup and teardown scenarios, sorted.
+// The paths are relative to the plugin directory.
+func (r *Runner) PluginPaths() ([]string, error) {
+	paths := map[string]struct{}{}
+	for _, p := range r.plugins {
+		paths[filepath.Clean(p)] = struct{}{}
+	}
+	files := append(append(append([]string{}, r.scenarioFiles...), r.setupFiles...), r.teardownFiles...)
+	for _, f := range files {
+		scns, err := schema.LoadScenarios(f, schema.WithStepLibrary(r.stepLibrary))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load scenarios: %w", err)
+		}
+		for _, scn := range scns {
+			for _, p := range scn.PluginPaths() {
+				paths[p] = struct{}{}
+			}
+		}
+	}
+	plugins := make([]string, 0, len(paths))
+	for p := range paths {
+		plugins = append(plugins, p)
+	}
+	sort.Strings(plugins)
+	return plugins, nil
 }
 
 // Run runs all tests.
 func (r *Runner) Run(ctx *context.Context) {
+	defer closeRPCPlugins()
 	if r.pluginDir != nil {
 		ctx = ctx.WithPluginDir(*r.pluginDir)
 	}
 	ctx = ctx.WithEnabledColor(r.enabledColor)
-	for _, f := range r.scenarioFiles {
-		testName, err := filepath.Rel(r.rootDir, f)
-		if err != nil {
-			ctx.Reporter().Fatalf("failed to load scenarios: %s", err)
+	if r.vars != nil {
+		ctx = ctx.WithVars(r.vars)
+	}
+	ctx = ctx.WithEnv(r.env)
+	if r.dryRun {
+		ctx = ctx.WithDryRun(true)
+	}
+	if r.keptVars != nil {
+		ctx = ctx.WithVars(r.keptVars.get())
+	}
+	// the included scenarios with inputs can also refer to the global variables
+	ctx = ctx.WithGlobalVars()
+	if len(r.setupFiles) > 0 {
+		vars, ok := r.runHooks(ctx, r.setupFiles)
+		ctx = ctx.WithReadOnlyVars(vars).WithGlobalVars()
+		if !ok {
+			r.runHooks(ctx, r.teardownFiles)
+			r.writeTestReport(ctx)
+			return
 		}
-		ctx.Run(testName, func(ctx *context.Context) {
-			scns, err := schema.LoadScenarios(f)
-			if err != nil {
-				ctx.Reporter().Fatalf("failed to load scenarios: %s", err)
+	}
+	// the teardown runs even if the scenarios are canceled by the fail fast
+	teardownCtx := ctx
+	cancel := func() {}
+	if r.failFast {
+		var reqCtx gocontext.Context
+		reqCtx, cancel = gocontext.WithCancel(ctx.RequestContext())
+		defer cancel()
+		ctx = ctx.WithRequestContext(reqCtx)
+	}
+	files := r.loadScenarioFiles(ctx)
+	only := hasOnly(files)
+	for _, f := range files {
+		f := f
+		ctx.Run(f.name, func(ctx *context.Context) {
+			if f.err != nil {
+				cancel()
+				ctx.Reporter().Fatalf("failed to load scenarios: %s", f.err)
 			}
-			for _, scn := range scns {
+			ctx = r.withPlugins(ctx)
+			for _, scn := range f.scenarios {
 				scn := scn
 				ctx = ctx.WithNode(scn.Node)
 				ctx.Run(scn.Title, func(ctx *context.Context) {
 					ctx.Reporter().Parallel()
-					_ = RunScenario(ctx, scn)
+					if only && !scn.Only {
+						ctx.Reporter().Skip("skipped because other scenarios are marked as only")
+					}
+					if r.failFast {
+						if ctx.RequestContext().Err() != nil {
+							ctx.Reporter().SkipWithReason(reporter.SkipReasonFailFast, "skipped because another scenario failed")
+						}
+						ctx = ctx.WithReporter(&failFastReporter{
+							Reporter: ctx.Reporter(),
+							ctx:      ctx.RequestContext(),
+						})
+						defer func() {
+							if ctx.Reporter().Failed() && scn.ExpectFailure == "" {
+								cancel()
+							}
+						}()
+					}
+					scnCtx := RunScenario(ctx, scn)
+					if r.failFast && ctx.RequestContext().Err() != nil && !ctx.Reporter().Failed() {
+						// the steps were canceled by the failure of another scenario
+						ctx.Reporter().SkipWithReason(reporter.SkipReasonFailFast, canceledByFailFast)
+					}
+					if r.keptVars != nil {
+						r.keptVars.keep(scnCtx, scn)
+					}
 				})
 			}
 		})
 	}
+	r.runHooks(teardownCtx, r.teardownFiles)
+	r.writeTestReport(ctx)
+}
+
+const canceledByFailFast = "canceled because another scenario failed"
+
+// failFastReporter reports the failures after the cancellation by the fail fast as skips
+// because they are caused by the cancellation, not by the scenario itself.
+type failFastReporter struct {
+	reporter.Reporter
+	ctx gocontext.Context
+}
+
+func (r *failFastReporter) skipIfCanceled() {
+	if r.ctx.Err() != nil {
+		r.Reporter.SkipWithReason(reporter.SkipReasonFailFast, canceledByFailFast)
+	}
+}
+
+// Fail implements reporter.Reporter interface.
+func (r *failFastReporter) Fail() {
+	r.skipIfCanceled()
+	r.Reporter.Fail()
+}
+
+// FailNow implements reporter.Reporter interface.
+func (r *failFastReporter) FailNow() {
+	r.skipIfCanceled()
+	r.Reporter.FailNow()
+}
+
+// Error implements reporter.Reporter interface.
+func (r *failFastReporter) Error(args ...interface{}) {
+	r.skipIfCanceled()
+	r.Reporter.Error(args...)
+}
+
+// Errorf implements reporter.Reporter interface.
+func (r *failFastReporter) Errorf(format string, args ...interface{}) {
+	r.skipIfCanceled()
+	r.Reporter.Errorf(format, args...)
+}
+
+// Fatal implements reporter.Reporter interface.
+func (r *failFastReporter) Fatal(args ...interface{}) {
+	r.skipIfCanceled()
+	r.Reporter.Fatal(args...)
+}
+
+// Fatalf implements reporter.Reporter interface.
+func (r *failFastReporter) Fatalf(format string, args ...interface{}) {
+	r.skipIfCanceled()
+	r.Reporter.Fatalf(format, args...)
+}
+
+// Run implements reporter.Reporter interface.
+func (r *failFastReporter) Run(name string, f func(reporter.Reporter)) bool {
+	return r.Reporter.Run(name, func(rptr reporter.Reporter) {
+		f(&failFastReporter{Reporter: rptr, ctx: r.ctx})
+	})
+}
+
+// withPlugins loads the plugins for all scenarios.
+// They are loaded for each file to report the errors as the failures of the file.
+func (r *Runner) withPlugins(ctx *context.Context) *context.Context {
+	if len(r.plugins) == 0 {
+		return ctx
+	}
+	return ctx.WithPlugins(loadPlugins(ctx, r.plugins))
+}
+
+// scenarioFile represents the loaded scenarios of a file or a reader.
+type scenarioFile struct {
+	name      string
+	scenarios []*schema.Scenario
+	err       error
+}
+
+// loadScenarioFiles loads all scenarios to run before running them.
+// Load errors are kept to report them as the failures of each file.
+func (r *Runner) loadScenarioFiles(ctx *context.Context) []*scenarioFile {
+	var files []*scenarioFile
+	for _, f := range r.ScenarioFiles() {
+		testName, err := filepath.Rel(r.rootDir, f)
+		if err != nil {
+			ctx.Reporter().Fatalf("failed to load scenarios: %s", err)
+		}
+		scns, err := schema.LoadScenarios(f, schema.WithStepLibrary(r.stepLibrary))
+		files = append(files, &scenarioFile{
+			name:      testName,
+			scenarios: r.filterScenarios(testName, scns),
+			err:       err,
+		})
+	}
 	for i, reader := range r.scenarioReaders {
-		ctx.Run(fmt.Sprint(i), func(ctx *context.Context) {
-			scns, err := schema.LoadScenariosFromReader(reader)
-			if err != nil {
-				ctx.Reporter().Fatalf("failed to load scenarios: %s", err)
-			}
-			for _, scn := range scns {
-				scn := scn
-				ctx = ctx.WithNode(scn.Node)
-				ctx.Run(scn.Title, func(ctx *context.Context) {
-					ctx.Reporter().Parallel()
-					_ = RunScenario(ctx, scn)
-				})
-			}
+		if !r.readerInShard(i) {
+			continue
+		}
+		testName := fmt.Sprint(i)
+		scns, err := schema.LoadScenariosFromReader(reader, schema.WithStepLibrary(r.stepLibrary))
+		files = append(files, &scenarioFile{
+			name:      testName,
+			scenarios: r.filterScenarios(testName, scns),
+			err:       err,
 		})
 	}
-	r.writeTestReport(ctx)
+	r.arrangeScenarios(files)
+	return files
+}
+
+// hasOnly reports whether any scenario is marked as only.
+func hasOnly(files []*scenarioFile) bool {
+	for _, f := range files {
+		for _, scn := range f.scenarios {
+			if scn.Only {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func (r *Runner) writeTestReport(ctx *context.Context) {
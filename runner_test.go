@@ -2,6 +2,7 @@ package scenarigo
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -11,8 +12,10 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 
 	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/plugin"
 	"github.com/zoncoen/scenarigo/reporter"
 	"github.com/zoncoen/scenarigo/schema"
 )
@@ -53,6 +56,7 @@ func TestRunner(t *testing.T) {
 	tests := map[string]struct {
 		path  string
 		yaml  string
+		opts  []func(*Runner) error
 		setup func(*testing.T) func()
 	}{
 		"run step with include": {
@@ -78,6 +82,55 @@ func TestRunner(t *testing.T) {
 				}
 			},
 		},
+		"run step with include with inputs and outputs": {
+			path: filepath.Join("testdata", "use_include_with.yaml"),
+			setup: func(t *testing.T) func() {
+				t.Helper()
+
+				mux := http.NewServeMux()
+				mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+					defer r.Body.Close()
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = io.Copy(w, r.Body)
+				})
+
+				s := httptest.NewServer(mux)
+				if err := os.Setenv("TEST_ADDR", s.URL); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				return func() {
+					s.Close()
+					os.Unsetenv("TEST_ADDR")
+				}
+			},
+		},
+		"run step with uses": {
+			path: filepath.Join("testdata", "use_step_library.yaml"),
+			opts: []func(*Runner) error{
+				WithStepLibraries(filepath.Join("testdata", "steps")),
+			},
+			setup: func(t *testing.T) func() {
+				t.Helper()
+
+				mux := http.NewServeMux()
+				mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+					defer r.Body.Close()
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = io.Copy(w, r.Body)
+				})
+
+				s := httptest.NewServer(mux)
+				if err := os.Setenv("TEST_ADDR", s.URL); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				return func() {
+					s.Close()
+					os.Unsetenv("TEST_ADDR")
+				}
+			},
+		},
 		"run with yaml": {
 			yaml: `
 ---
@@ -121,7 +174,7 @@ steps:
 		teardown := test.setup(t)
 		defer teardown()
 
-		var opts []func(*Runner) error
+		opts := test.opts
 		if test.path != "" {
 			opts = append(opts, WithScenarios(test.path))
 		}
@@ -171,6 +224,10 @@ func TestRunnerFail(t *testing.T) {
 				}
 			},
 		},
+		"include cycle": {
+			path:  filepath.Join("testdata", "use_include_cycle.yaml"),
+			setup: func(t *testing.T) func() { return func() {} },
+		},
 		"run with yaml": {
 			yaml:  `invalid: value`,
 			setup: func(t *testing.T) func() { return func() {} },
@@ -254,6 +311,7 @@ func TestWithConfig(t *testing.T) {
 				Scenarios: []string{"testdata/use_include.yaml"},
 			},
 			expect: &Runner{
+				scenarioPaths: []string{filepath.Join(wd, "testdata/use_include.yaml")},
 				scenarioFiles: []string{filepath.Join(wd, "testdata/use_include.yaml")},
 				rootDir:       wd,
 			},
@@ -315,6 +373,7 @@ func TestWithConfig(t *testing.T) {
 			}
 			if diff := cmp.Diff(test.expect, got,
 				cmp.AllowUnexported(Runner{}),
+				cmpopts.IgnoreFields(Runner{}, "opts"),
 			); diff != "" {
 				t.Errorf("differs (-want +got):\n%s", diff)
 			}
@@ -397,3 +456,329 @@ func TestWriteTestReport(t *testing.T) {
 		})
 	}
 }
+
+func TestRunner_Markers(t *testing.T) {
+	tests := map[string]struct {
+		yaml    string
+		success bool
+		expect  map[string]reporter.TestResult
+	}{
+		"skip": {
+			yaml: `
+---
+title: skipped
+skip: not implemented yet
+steps:
+- ref: '{{plugins.fail}}'
+---
+title: passed
+steps:
+- title: skipped step
+  skip: not implemented yet
+  ref: '{{plugins.fail}}'
+- ref: '{{plugins.pass}}'
+`,
+			success: true,
+			expect: map[string]reporter.TestResult{
+				"skipped": reporter.TestResultSkipped,
+				"passed":  reporter.TestResultPassed,
+			},
+		},
+		"only": {
+			yaml: `
+---
+title: skipped
+steps:
+- ref: '{{plugins.fail}}'
+---
+title: only
+only: true
+steps:
+- ref: '{{plugins.fail}}'
+- ref: '{{plugins.pass}}'
+  only: true
+`,
+			success: true,
+			expect: map[string]reporter.TestResult{
+				"skipped": reporter.TestResultSkipped,
+				"only":    reporter.TestResultPassed,
+			},
+		},
+		"expect failure": {
+			yaml: `
+---
+title: expected failure
+expectFailure: ISSUE-1
+steps:
+- ref: '{{plugins.fail}}'
+---
+title: unexpected pass
+expectFailure: ISSUE-2
+steps:
+- ref: '{{plugins.pass}}'
+`,
+			success: true,
+			expect: map[string]reporter.TestResult{
+				"expected failure": reporter.TestResultExpectedFailure,
+				"unexpected pass":  reporter.TestResultUnexpectedPass,
+			},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			runner, err := NewRunner(WithScenariosFromReader(strings.NewReader(test.yaml)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var (
+				b      bytes.Buffer
+				report *reporter.TestReport
+			)
+			ok := reporter.Run(func(rptr reporter.Reporter) {
+				runner.Run(context.New(rptr).WithPlugins(map[string]interface{}{
+					"pass": plugin.StepFunc(func(ctx *context.Context, step *schema.Step) *context.Context {
+						return ctx
+					}),
+					"fail": plugin.StepFunc(func(ctx *context.Context, step *schema.Step) *context.Context {
+						ctx.Reporter().FailNow()
+						return ctx
+					}),
+				}))
+				report, err = reporter.GenerateTestReport(rptr)
+			}, reporter.WithWriter(&b))
+			if err != nil {
+				t.Fatalf("failed to generate report: %s", err)
+			}
+			if ok != test.success {
+				t.Fatalf("expect %t but got %t:\n%s", test.success, ok, b.String())
+			}
+			got := map[string]reporter.TestResult{}
+			for _, f := range report.Files {
+				for _, scn := range f.Scenarios {
+					got[scn.Name] = scn.Result
+				}
+			}
+			if diff := cmp.Diff(test.expect, got); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRunner_FailFast(t *testing.T) {
+	tests := map[string]struct {
+		yamls  []string
+		expect map[string]reporter.TestResult
+	}{
+		"skip remaining scenarios": {
+			yamls: []string{`
+title: failed
+steps:
+- ref: '{{plugins.fail}}'
+`, `
+title: skipped
+steps:
+- ref: '{{plugins.pass}}'
+`},
+			expect: map[string]reporter.TestResult{
+				"failed":  reporter.TestResultFailed,
+				"skipped": reporter.TestResultSkipped,
+			},
+		},
+		"cancel running scenarios": {
+			yamls: []string{`
+---
+title: canceled
+steps:
+- ref: '{{plugins.wait}}'
+---
+title: failed
+steps:
+- ref: '{{plugins.fail}}'
+`, `
+title: skipped
+steps:
+- ref: '{{plugins.pass}}'
+`},
+			expect: map[string]reporter.TestResult{
+				"canceled": reporter.TestResultSkipped,
+				"failed":   reporter.TestResultFailed,
+				"skipped":  reporter.TestResultSkipped,
+			},
+		},
+		"expected failure": {
+			yamls: []string{`
+title: expected failure
+expectFailure: ISSUE-1
+steps:
+- ref: '{{plugins.fail}}'
+`, `
+title: passed
+steps:
+- ref: '{{plugins.pass}}'
+`},
+			expect: map[string]reporter.TestResult{
+				"expected failure": reporter.TestResultExpectedFailure,
+				"passed":           reporter.TestResultPassed,
+			},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			readers := make([]io.Reader, len(test.yamls))
+			for i, y := range test.yamls {
+				readers[i] = strings.NewReader(y)
+			}
+			runner, err := NewRunner(
+				WithScenariosFromReader(readers...),
+				WithFailFast(true),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var (
+				b      bytes.Buffer
+				report *reporter.TestReport
+			)
+			reporter.Run(func(rptr reporter.Reporter) {
+				runner.Run(context.New(rptr).WithPlugins(map[string]interface{}{
+					"pass": plugin.StepFunc(func(ctx *context.Context, step *schema.Step) *context.Context {
+						return ctx
+					}),
+					"fail": plugin.StepFunc(func(ctx *context.Context, step *schema.Step) *context.Context {
+						ctx.Reporter().FailNow()
+						return ctx
+					}),
+					"wait": plugin.StepFunc(func(ctx *context.Context, step *schema.Step) *context.Context {
+						<-ctx.RequestContext().Done()
+						ctx.Reporter().Fatal(ctx.RequestContext().Err())
+						return ctx
+					}),
+				}))
+				report, err = reporter.GenerateTestReport(rptr)
+			}, reporter.WithWriter(&b), reporter.WithMaxParallel(2))
+			if err != nil {
+				t.Fatalf("failed to generate report: %s", err)
+			}
+			got := map[string]reporter.TestResult{}
+			for _, f := range report.Files {
+				for _, scn := range f.Scenarios {
+					got[scn.Name] = scn.Result
+				}
+			}
+			if diff := cmp.Diff(test.expect, got); diff != "" {
+				t.Errorf("differs (-want +got):\n%s\n%s", diff, b.String())
+			}
+		})
+	}
+}
+
+func TestRunner_FailFast_RerunFailed(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"passed.yaml": `
+title: passed
+steps:
+- ref: '{{plugins.pass}}'
+`,
+		"failed.yaml": `
+---
+title: canceled
+steps:
+- ref: '{{plugins.wait}}'
+---
+title: failed
+steps:
+- ref: '{{plugins.fail}}'
+`,
+		"skipped.yaml": `
+title: skipped
+steps:
+- ref: '{{plugins.pass}}'
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	paths := []string{
+		filepath.Join(dir, "passed.yaml"),
+		filepath.Join(dir, "failed.yaml"),
+		filepath.Join(dir, "skipped.yaml"),
+	}
+	pass := plugin.StepFunc(func(ctx *context.Context, step *schema.Step) *context.Context {
+		return ctx
+	})
+	run := func(t *testing.T, plugins map[string]interface{}, opts ...func(*Runner) error) map[string]reporter.TestResult {
+		t.Helper()
+		runner, err := NewRunner(append([]func(*Runner) error{WithScenarios(paths...)}, opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var (
+			b      bytes.Buffer
+			report *reporter.TestReport
+		)
+		reporter.Run(func(rptr reporter.Reporter) {
+			runner.Run(context.New(rptr).WithPlugins(plugins))
+			report, err = reporter.GenerateTestReport(rptr)
+		}, reporter.WithWriter(&b), reporter.WithMaxParallel(2))
+		if err != nil {
+			t.Fatalf("failed to generate report: %s", err)
+		}
+		f, err := os.Create(filepath.Join(dir, "report.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := json.NewEncoder(f).Encode(report); err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]reporter.TestResult{}
+		for _, f := range report.Files {
+			for _, scn := range f.Scenarios {
+				got[scn.Name] = scn.Result
+			}
+		}
+		return got
+	}
+
+	got := run(t, map[string]interface{}{
+		"pass": pass,
+		"fail": plugin.StepFunc(func(ctx *context.Context, step *schema.Step) *context.Context {
+			ctx.Reporter().FailNow()
+			return ctx
+		}),
+		"wait": plugin.StepFunc(func(ctx *context.Context, step *schema.Step) *context.Context {
+			<-ctx.RequestContext().Done()
+			ctx.Reporter().Fatal(ctx.RequestContext().Err())
+			return ctx
+		}),
+	}, WithFailFast(true))
+	expect := map[string]reporter.TestResult{
+		"passed":   reporter.TestResultPassed,
+		"canceled": reporter.TestResultSkipped,
+		"failed":   reporter.TestResultFailed,
+		"skipped":  reporter.TestResultSkipped,
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Fatalf("differs (-want +got):\n%s", diff)
+	}
+
+	got = run(t, map[string]interface{}{
+		"pass": pass,
+		"fail": pass,
+		"wait": pass,
+	}, WithRerunFailed(filepath.Join(dir, "report.json")))
+	expect = map[string]reporter.TestResult{
+		"canceled": reporter.TestResultPassed,
+		"failed":   reporter.TestResultPassed,
+		"skipped":  reporter.TestResultPassed,
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("differs (-want +got):\n%s", diff)
+	}
+}
@@ -1,14 +1,21 @@
 package scenarigo
 
 import (
+	"debug/elf"
+	"debug/macho"
 	"fmt"
 	"path/filepath"
 	"plugin"
 	"reflect"
+	"sort"
 	"sync"
 
+	"github.com/lestrrat-go/backoff"
+	"golang.org/x/xerrors"
+
 	"github.com/zoncoen/scenarigo/context"
 	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/plugin/rpcplugin"
 	"github.com/zoncoen/scenarigo/schema"
 )
 
@@ -29,23 +36,166 @@ func loadPlugin(ctx *context.Context, path string) *plugin.Plugin {
 	return p
 }
 
+var (
+	rpcPlgMu   sync.Mutex
+	rpcPlugins = map[string]*rpcplugin.Plugin{}
+)
+
+// loadRPCPlugin starts the out-of-process plugin.
+// The plugin process is shared by all scenarios until closeRPCPlugins is called.
+func loadRPCPlugin(ctx *context.Context, path string) *rpcplugin.Plugin {
+	rpcPlgMu.Lock()
+	defer rpcPlgMu.Unlock()
+	if p, ok := rpcPlugins[path]; ok {
+		return p
+	}
+	p, err := rpcplugin.Open(path)
+	if err != nil {
+		ctx.Reporter().Fatalf("failed to open plugin: %s", err)
+	}
+	rpcPlugins[path] = p
+	return p
+}
+
+// closeRPCPlugins stops all out-of-process plugins.
+func closeRPCPlugins() {
+	rpcPlgMu.Lock()
+	defer rpcPlgMu.Unlock()
+	for path, p := range rpcPlugins {
+		_ = p.Close()
+		delete(rpcPlugins, path)
+	}
+}
+
+// loadPlugins loads the plugins of paths relative to the plugin directory.
+// The files which are not shared objects like executables are loaded as out-of-process plugins.
+func loadPlugins(ctx *context.Context, paths map[string]string) map[string]interface{} {
+	plugs := map[string]interface{}{}
+	for name, path := range paths {
+		path := path
+		if root := ctx.PluginDir(); root != "" {
+			path = filepath.Join(root, path)
+		}
+		if !isSharedObject(path) {
+			plugs[name] = loadRPCPlugin(ctx, path)
+			continue
+		}
+		p := loadPlugin(ctx, path)
+		plugs[name] = &plug{p}
+	}
+	return plugs
+}
+
+// isSharedObject reports whether the file of path is a shared object which can be loaded as a Go plugin.
+// The files with the ".so" extension are always treated as shared objects without reading them.
+// Otherwise, the file is inspected because the executables built as position independent are also ELF shared objects,
+// and they are distinguished by the program interpreter which only executables have.
+func isSharedObject(path string) bool {
+	if filepath.Ext(path) == ".so" {
+		return true
+	}
+	if f, err := elf.Open(path); err == nil {
+		defer f.Close()
+		if f.Type != elf.ET_DYN {
+			return false
+		}
+		for _, p := range f.Progs {
+			if p.Type == elf.PT_INTERP {
+				return false
+			}
+		}
+		return true
+	}
+	if f, err := macho.Open(path); err == nil {
+		defer f.Close()
+		return f.Type == macho.TypeDylib || f.Type == macho.TypeBundle
+	}
+	return false
+}
+
 // RunScenario runs a test scenario s.
 func RunScenario(ctx *context.Context, s *schema.Scenario) *context.Context {
 	ctx = ctx.WithScenarioFilepath(s.Filepath())
+	if s.Skip != "" {
+		ctx.Reporter().Skip(s.Skip)
+	}
+	if s.ExpectFailure != "" {
+		ctx.Reporter().ExpectFailure(s.ExpectFailure)
+	}
 	if s.Plugins != nil {
-		plugs := map[string]interface{}{}
-		for name, path := range s.Plugins {
-			path := path
-			if root := ctx.PluginDir(); root != "" {
-				path = filepath.Join(root, path)
-			}
-			p := loadPlugin(ctx, path)
-			plugs[name] = &plug{p}
+		ctx = ctx.WithPlugins(loadPlugins(ctx, s.Plugins))
+	}
+
+	if s.Retry != nil && (s.Retry.Until != nil || len(s.Retry.On) > 0) {
+		ctx.Reporter().Fatal("invalid retry policy: until and on are not available for scenarios")
+	}
+	policy, err := s.Retry.Build()
+	if err != nil {
+		ctx.Reporter().Fatal(xerrors.Errorf("invalid retry policy: %w", err))
+	}
+	if s.Retry != nil {
+		ctx.Reporter().EnableRetry()
+	}
+
+	ctx = beforeScenario(ctx, s)
+	scnCtx := ctx
+	// the scenario may end by runtime.Goexit on fatal errors
+	defer func() {
+		afterScenario(scnCtx, s)
+	}()
+
+	b, cancel := policy.Start(ctx.RequestContext())
+	defer cancel()
+
+	var (
+		i      int
+		failed bool
+	)
+	for backoff.Continue(b) {
+		if i > 0 {
+			// run the scenario again with fresh vars
+			ctx.Reporter().Retry()
+			ctx.Reporter().Logf("[%d] retry the scenario", i)
+		}
+		i++
+		scnCtx, failed = runSteps(ctx, s)
+		if !failed {
+			break
 		}
-		ctx = ctx.WithPlugins(plugs)
 	}
 
+	if s.ExpectFailure != "" && !failed {
+		scnCtx.Reporter().Logf("the scenario passed unexpectedly, so the expectFailure marker (%s) can be removed", s.ExpectFailure)
+	}
+
+	return scnCtx
+}
+
+// checkReadOnlyVars fails the test if vars override the read-only variables bound by the setup scenarios.
+func checkReadOnlyVars(ctx *context.Context, path string, vars map[string]interface{}) {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if ctx.Vars().ReadOnly(name) {
+			ctx.Reporter().Fatal(
+				errors.WithNodeAndColored(
+					errors.ErrorPathf(fmt.Sprintf("%s.%s", path, name), "variable %q is bound by the setup scenarios and can't be overridden", name),
+					ctx.Node(),
+					ctx.EnabledColor(),
+				),
+			)
+		}
+	}
+}
+
+// runSteps evaluates the scenario vars and runs the steps of s.
+// It reports whether any step failed.
+func runSteps(ctx *context.Context, s *schema.Scenario) (*context.Context, bool) {
 	if s.Vars != nil {
+		checkReadOnlyVars(ctx, "vars", s.Vars)
 		vars, err := ctx.ExecuteTemplate(s.Vars)
 		if err != nil {
 			ctx.Reporter().Fatalf("invalid vars: %s", err)
@@ -53,22 +203,45 @@ func RunScenario(ctx *context.Context, s *schema.Scenario) *context.Context {
 		ctx = ctx.WithVars(vars)
 	}
 
+	var hasOnly bool
+	for _, step := range s.Steps {
+		if step.Only {
+			hasOnly = true
+			break
+		}
+	}
+
 	scnCtx := ctx
-	var failed bool
+	var failed, stopped bool
 	for idx, step := range s.Steps {
 		step := step
+		var completed bool
 		ok := scnCtx.Run(step.Title, func(ctx *context.Context) {
 			// following steps are skipped if the previous step failed
-			if failed {
+			if stopped {
 				ctx.Reporter().SkipNow()
 			}
+			if step.Skip != "" {
+				ctx.Reporter().Skip(step.Skip)
+			}
+			if hasOnly && !step.Only {
+				ctx.Reporter().Skip("skipped because other steps are marked as only")
+			}
 
+			ctx = beforeStep(ctx, step)
+			defer func() {
+				afterStep(ctx, step)
+			}()
 			ctx = runStep(ctx, s, step, idx)
 
 			// bind values to the scenario context for enable to access from following steps
 			if step.Bind.Vars != nil {
+				checkReadOnlyVars(ctx, fmt.Sprintf("steps[%d].bind.vars", idx), step.Bind.Vars)
 				vars, err := ctx.ExecuteTemplate(step.Bind.Vars)
-				if err != nil {
+				if err != nil && ctx.DryRun() {
+					// the bind may refer to the response which doesn't exist in the dry-run mode
+					ctx.Reporter().Logf("failed to bind: %s", err)
+				} else if err != nil {
 					ctx.Reporter().Fatal(
 						errors.WithNodeAndColored(
 							errors.WrapPath(
@@ -83,13 +256,18 @@ func RunScenario(ctx *context.Context, s *schema.Scenario) *context.Context {
 				}
 				scnCtx = scnCtx.WithVars(vars)
 			}
+			completed = true
 		})
-		if !failed {
-			failed = !ok
+		if !ok {
+			failed = true
+			// the step which failed softly runs to the end, so following steps can continue in the soft mode
+			if !step.ContinueOnError && !(s.Soft && completed) {
+				stopped = true
+			}
 		}
 	}
 
-	return scnCtx
+	return scnCtx, failed
 }
 
 // lookupper is an interface wrapper around *plugin.Plugin.
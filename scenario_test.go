@@ -2,9 +2,16 @@ package scenarigo
 
 import (
 	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	"github.com/zoncoen/scenarigo/context"
 	"github.com/zoncoen/scenarigo/plugin"
 	"github.com/zoncoen/scenarigo/reporter"
@@ -57,3 +64,336 @@ func createTempScenario(t *testing.T, scenario string) string {
 	}
 	return f.Name()
 }
+
+func TestRunScenario_ContinueOnError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.Copy(w, r.Body)
+	}))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		yaml   string
+		expect []reporter.TestResult
+	}{
+		"default": {
+			yaml: `
+steps:
+- protocol: http
+  request:
+    method: POST
+    url: "{{vars.url}}"
+    body:
+      message: hello
+  expect:
+    body:
+      message: bye
+- protocol: http
+  request:
+    method: POST
+    url: "{{vars.url}}"
+`,
+			expect: []reporter.TestResult{
+				reporter.TestResultFailed,
+				reporter.TestResultSkipped,
+			},
+		},
+		"continue on error": {
+			yaml: `
+steps:
+- protocol: http
+  request:
+    method: POST
+    url: "{{vars.url}}"
+    body:
+      message: hello
+  expect:
+    body:
+      message: bye
+  continueOnError: true
+- protocol: http
+  request:
+    method: POST
+    url: "{{vars.url}}"
+`,
+			expect: []reporter.TestResult{
+				reporter.TestResultFailed,
+				reporter.TestResultPassed,
+			},
+		},
+		"soft": {
+			yaml: `
+soft: true
+steps:
+- protocol: http
+  request:
+    method: POST
+    url: "{{vars.url}}"
+    body:
+      message: hello
+  expect:
+    body:
+      message: bye
+  bind:
+    vars:
+      message: "{{response.message}}"
+- protocol: http
+  request:
+    method: POST
+    url: "{{vars.url}}"
+    body:
+      message: "{{vars.message}}"
+  expect:
+    body:
+      message: hello
+- protocol: http
+  request:
+    method: POST
+    url: "{{vars.unknown}}"
+- protocol: http
+  request:
+    method: POST
+    url: "{{vars.url}}"
+`,
+			expect: []reporter.TestResult{
+				reporter.TestResultFailed,
+				reporter.TestResultPassed,
+				reporter.TestResultFailed,
+				reporter.TestResultSkipped,
+			},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			scenarios, err := schema.LoadScenariosFromReader(strings.NewReader(test.yaml))
+			if err != nil {
+				t.Fatalf("failed to load scenario: %s", err)
+			}
+			var report *reporter.TestReport
+			ok := reporter.Run(func(rptr reporter.Reporter) {
+				ctx := context.New(rptr).WithVars(map[string]string{"url": srv.URL})
+				ctx.Run("file", func(ctx *context.Context) {
+					ctx.Run("scenario", func(ctx *context.Context) {
+						RunScenario(ctx, scenarios[0])
+					})
+				})
+				report, err = reporter.GenerateTestReport(rptr)
+			})
+			if ok {
+				t.Fatal("expected failure but succeeded")
+			}
+			if err != nil {
+				t.Fatalf("failed to generate report: %s", err)
+			}
+			var got []reporter.TestResult
+			for _, step := range report.Files[0].Scenarios[0].Steps {
+				got = append(got, step.Result)
+			}
+			if diff := cmp.Diff(test.expect, got); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRunScenario_Retry(t *testing.T) {
+	tests := map[string]struct {
+		maxRetries    int
+		expectOK      bool
+		expectResult  reporter.TestResult
+		expectRetries int
+	}{
+		"flaky": {
+			maxRetries:    2,
+			expectOK:      true,
+			expectResult:  reporter.TestResultFlaky,
+			expectRetries: 2,
+		},
+		"failed": {
+			maxRetries:    1,
+			expectOK:      false,
+			expectResult:  reporter.TestResultFailed,
+			expectRetries: 1,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			var count int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// fail the first two requests
+				count++
+				if count <= 2 {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}))
+			defer srv.Close()
+
+			scenarios, err := schema.LoadScenariosFromReader(strings.NewReader(fmt.Sprintf(`
+retry:
+  constant:
+    interval: 1ms
+    maxRetries: %d
+steps:
+- protocol: http
+  request:
+    method: GET
+    url: "{{vars.url}}"
+  expect:
+    code: OK
+`, test.maxRetries)))
+			if err != nil {
+				t.Fatalf("failed to load scenario: %s", err)
+			}
+			var report *reporter.TestReport
+			ok := reporter.Run(func(rptr reporter.Reporter) {
+				ctx := context.New(rptr).WithVars(map[string]string{"url": srv.URL})
+				ctx.Run("file", func(ctx *context.Context) {
+					ctx.Run("scenario", func(ctx *context.Context) {
+						RunScenario(ctx, scenarios[0])
+					})
+				})
+				report, err = reporter.GenerateTestReport(rptr)
+			})
+			if ok != test.expectOK {
+				t.Fatalf("expect %t but got %t", test.expectOK, ok)
+			}
+			if err != nil {
+				t.Fatalf("failed to generate report: %s", err)
+			}
+			scn := report.Files[0].Scenarios[0]
+			if got, expect := scn.Result, test.expectResult; got != expect {
+				t.Errorf("expect %s but got %s", expect, got)
+			}
+			if got, expect := scn.Retries, test.expectRetries; got != expect {
+				t.Errorf("expect %d retries but got %d", expect, got)
+			}
+			if got := len(scn.Steps); got != 1 {
+				t.Errorf("expect the steps of the last attempt but got %d steps", got)
+			}
+		})
+	}
+}
+
+func TestRunScenario_Wait(t *testing.T) {
+	tests := map[string]struct {
+		yaml     string
+		expectOK bool
+	}{
+		"wait": {
+			yaml: `
+steps:
+- wait: 1ms
+`,
+			expectOK: true,
+		},
+		"invalid wait": {
+			yaml: `
+steps:
+- wait: 1
+`,
+			expectOK: false,
+		},
+		"wait for the request": {
+			yaml: `
+steps:
+- waitFor:
+    constant:
+      interval: 1ms
+      maxRetries: 5
+  protocol: http
+  request:
+    method: GET
+    url: "{{vars.url}}"
+  expect:
+    code: OK
+`,
+			expectOK: true,
+		},
+		"wait for the condition": {
+			yaml: `
+vars:
+  ready: true
+steps:
+- waitFor:
+    condition: "{{vars.ready}}"
+`,
+			expectOK: true,
+		},
+		"give up waiting": {
+			yaml: `
+vars:
+  ready: false
+steps:
+- waitFor:
+    condition: "{{vars.ready}}"
+    constant:
+      interval: 1ms
+      maxRetries: 1
+`,
+			expectOK: false,
+		},
+		"no condition": {
+			yaml: `
+steps:
+- waitFor: {}
+`,
+			expectOK: false,
+		},
+		"wait with request": {
+			yaml: `
+steps:
+- wait: 1ms
+  protocol: http
+  request:
+    method: GET
+    url: "{{vars.url}}"
+`,
+			expectOK: false,
+		},
+		"wait for with retry": {
+			yaml: `
+vars:
+  ready: true
+steps:
+- waitFor:
+    condition: "{{vars.ready}}"
+  retry:
+    constant:
+      interval: 1ms
+`,
+			expectOK: false,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			var count int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// not ready for the first two requests
+				count++
+				if count <= 2 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+			defer srv.Close()
+
+			scenarios, err := schema.LoadScenariosFromReader(strings.NewReader(test.yaml))
+			if err != nil {
+				t.Fatalf("failed to load scenario: %s", err)
+			}
+			var log bytes.Buffer
+			ok := reporter.Run(func(rptr reporter.Reporter) {
+				ctx := context.New(rptr).WithVars(map[string]string{"url": srv.URL})
+				ctx.Run("scenario", func(ctx *context.Context) {
+					RunScenario(ctx, scenarios[0])
+				})
+			}, reporter.WithWriter(&log))
+			if ok != test.expectOK {
+				t.Fatalf("expect %t but got %t:\n%s", test.expectOK, ok, log.String())
+			}
+		})
+	}
+}
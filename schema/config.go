@@ -23,13 +23,29 @@ func init() {
 
 // Config represents a configuration.
 type Config struct {
-	SchemaVersion   string       `yaml:"schemaVersion,omitempty"`
-	Scenarios       []string     `yaml:"scenarios,omitempty"`
-	PluginDirectory string       `yaml:"pluginDirectory,omitempty"`
-	Output          OutputConfig `yaml:"output,omitempty"`
+	SchemaVersion   string                   `yaml:"schemaVersion,omitempty"`
+	Scenarios       []string                 `yaml:"scenarios,omitempty"`
+	PluginDirectory string                   `yaml:"pluginDirectory,omitempty"`
+	Plugins         map[string]string        `yaml:"plugins,omitempty"`
+	PluginSources   map[string]string        `yaml:"pluginSources,omitempty"`
+	StepLibraries   []string                 `yaml:"stepLibraries,omitempty"`
+	Setup           []string                 `yaml:"setup,omitempty"`
+	Teardown        []string                 `yaml:"teardown,omitempty"`
+	Vars            map[string]interface{}   `yaml:"vars,omitempty"`
+	VarsFiles       []string                 `yaml:"varsFiles,omitempty"`
+	EnvFiles        []string                 `yaml:"envFiles,omitempty"`
+	Profiles        map[string]ProfileConfig `yaml:"profiles,omitempty"`
+	Output          OutputConfig             `yaml:"output,omitempty"`
 
-	// absolute path to the configuration file
+	// absolute path to the directory of the configuration file
 	Root string `yaml:"-"`
+	// absolute path to the configuration file
+	Path string `yaml:"-"`
+}
+
+// ProfileConfig represents a profile which overlays the configuration like "local" and "staging".
+type ProfileConfig struct {
+	Vars map[string]interface{} `yaml:"vars,omitempty"`
 }
 
 // OutputConfig represents a output configuration.
@@ -91,7 +107,7 @@ func LoadConfig(path string, colored bool) (*Config, error) {
 		)
 	}
 
-	root, err := filepath.Abs(filepath.Dir(path))
+	abs, err := filepath.Abs(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get root directory: %w", err)
 	}
@@ -102,7 +118,8 @@ func LoadConfig(path string, colored bool) (*Config, error) {
 		if err := yaml.NodeToValue(f.Docs[0].Body, &cfg, yaml.Strict()); err != nil {
 			return nil, err
 		}
-		cfg.Root = root
+		cfg.Root = filepath.Dir(abs)
+		cfg.Path = abs
 		return &cfg, nil
 	default:
 		return nil, errors.WithNodeAndColored(
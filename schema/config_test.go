@@ -26,6 +26,37 @@ func TestLoadConfig(t *testing.T) {
 				"b.yaml",
 			},
 			PluginDirectory: "plugins",
+			Plugins: map[string]string{
+				"hooks": "hooks.so",
+			},
+			PluginSources: map[string]string{
+				"hooks.so": "src/hooks",
+			},
+			StepLibraries: []string{
+				"steps",
+			},
+			Setup: []string{
+				"setup.yaml",
+			},
+			Teardown: []string{
+				"teardown.yaml",
+			},
+			Vars: map[string]interface{}{
+				"url": "http://localhost",
+			},
+			VarsFiles: []string{
+				"vars.yaml",
+			},
+			EnvFiles: []string{
+				".env",
+			},
+			Profiles: map[string]ProfileConfig{
+				"staging": {
+					Vars: map[string]interface{}{
+						"url": "https://staging.example.com",
+					},
+				},
+			},
 			Output: OutputConfig{
 				Verbose: true,
 				Colored: &colored,
@@ -39,6 +70,7 @@ func TestLoadConfig(t *testing.T) {
 				},
 			},
 			Root: filepath.Join(wd, "testdata/config"),
+			Path: filepath.Join(wd, "testdata/config/valid.yaml"),
 		}
 		if diff := cmp.Diff(expect, got); diff != "" {
 			t.Errorf("differs (-want +got):\n%s", diff)
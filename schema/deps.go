@@ -0,0 +1,59 @@
+package schema
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// Dependencies returns the paths of the files which the scenario depends on,
+// the included files and the plugins, sorted.
+// The included files are resolved recursively. The paths of plugins are joined with pluginDir.
+func (s *Scenario) Dependencies(pluginDir string) []string {
+	deps := map[string]struct{}{}
+	plugins := map[string]struct{}{}
+	s.dependencies(deps, plugins)
+	for p := range plugins {
+		deps[filepath.Join(pluginDir, p)] = struct{}{}
+	}
+	return sortedKeys(deps)
+}
+
+// PluginPaths returns the paths of the plugins which the scenario and the included files use, sorted.
+// The paths are relative to the plugin directory.
+func (s *Scenario) PluginPaths() []string {
+	plugins := map[string]struct{}{}
+	s.dependencies(map[string]struct{}{}, plugins)
+	return sortedKeys(plugins)
+}
+
+func (s *Scenario) dependencies(deps, plugins map[string]struct{}) {
+	for _, p := range s.Plugins {
+		plugins[filepath.Clean(p)] = struct{}{}
+	}
+	for _, step := range s.Steps {
+		if step.Include == "" {
+			continue
+		}
+		path := s.IncludePath(step.Include)
+		if _, ok := deps[path]; ok {
+			continue
+		}
+		deps[path] = struct{}{}
+		scns, err := LoadScenarios(path, WithStepLibrary(s.library))
+		if err != nil {
+			continue
+		}
+		for _, scn := range scns {
+			scn.dependencies(deps, plugins)
+		}
+	}
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
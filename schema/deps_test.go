@@ -0,0 +1,25 @@
+package schema
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestScenario_Dependencies(t *testing.T) {
+	scns, err := LoadScenarios("testdata/deps/scenario.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{
+		filepath.Join("plugins", "other.so"),
+		filepath.Join("plugins", "plugin.so"),
+		filepath.Join("testdata", "deps", "included.yaml"),
+		filepath.Join("testdata", "deps", "nested", "nested.yaml"),
+		filepath.Join("testdata", "deps", "nested", "not-found.yaml"),
+	}
+	if diff := cmp.Diff(expect, scns[0].Dependencies("plugins")); diff != "" {
+		t.Errorf("differs (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,455 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
+	"github.com/pkg/errors"
+
+	"github.com/zoncoen/scenarigo/template"
+)
+
+const formatIndent = 2
+
+var (
+	scenarioKeyOrder = keyOrder(reflect.TypeOf(Scenario{}))
+	stepKeyOrder     = keyOrder(reflect.TypeOf(Step{}))
+
+	templateLeftSpacePattern  = regexp.MustCompile(`\{\{\s+`)
+	templateRightSpacePattern = regexp.MustCompile(`\s+\}\}`)
+
+	// literalKeys are the keys of scenarios and steps whose values are not evaluated as templates.
+	literalKeys = map[string]bool{
+		"title":         true,
+		"description":   true,
+		"tags":          true,
+		"skip":          true,
+		"expectFailure": true,
+		"plugins":       true,
+		"include":       true,
+		"uses":          true,
+	}
+)
+
+// keyOrder returns the order of the keys of the struct type by the "yaml" struct tags.
+// "anchors" comes first because anchors must be defined before they are referred.
+func keyOrder(t reflect.Type) map[string]int {
+	order := map[string]int{"anchors": -1}
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok := t.Field(i).Tag.Lookup("yaml")
+		if !ok {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if _, ok := order[name]; !ok {
+			order[name] = i
+		}
+	}
+	return order
+}
+
+// Format formats the scenario YAML src into the canonical form.
+// The keys of scenarios and steps are ordered like the fields of Scenario and Step, the indentation is two spaces,
+// and the spaces around template parameters are removed like "{{vars.name}}".
+// The spaces are kept in the fields which are not evaluated as templates like the title and the description,
+// and in the strings which are not valid templates.
+// Comments and anchors are preserved.
+func Format(src []byte) ([]byte, error) {
+	f, err := parser.ParseBytes(src, parser.ParseComments)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse YAML")
+	}
+	var fm formatter
+	for i, doc := range f.Docs {
+		if i > 0 || doc.Start != nil {
+			fm.buf.WriteString("---\n")
+		}
+		if doc.Body == nil {
+			continue
+		}
+		fm.printBlock(doc.Body, 0, scenarioKeyOrder)
+	}
+	b := fm.buf.Bytes()
+
+	// ensure that formatting doesn't change the content
+	if err := equivalent(src, b); err != nil {
+		return nil, errors.Wrap(err, "failed to format")
+	}
+	return b, nil
+}
+
+// formatter prints YAML nodes in the canonical form.
+type formatter struct {
+	buf bytes.Buffer
+	// literal reports whether the nodes are in the field which is not evaluated as templates.
+	literal bool
+}
+
+// printBlock prints node which starts at the beginning of a line.
+// order is the key order of node if it is a mapping, or the key order of the items if it is a sequence.
+func (f *formatter) printBlock(node ast.Node, indent int, order map[string]int) {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		if n.IsFlowStyle || len(n.Values) == 0 {
+			f.printIndent(indent)
+			f.buf.WriteString(f.flowString(n))
+			f.buf.WriteString("\n")
+			return
+		}
+		f.printComment(n.Comment, indent)
+		for _, v := range f.sortMappingValues(n.Values, order) {
+			f.printMappingValue(v, indent, order)
+		}
+	case *ast.MappingValueNode:
+		f.printMappingValue(n, indent, order)
+	case *ast.SequenceNode:
+		if n.IsFlowStyle || len(n.Values) == 0 {
+			f.printIndent(indent)
+			f.buf.WriteString(f.flowString(n))
+			f.buf.WriteString("\n")
+			return
+		}
+		f.printSequence(n, indent, order)
+	default:
+		f.printIndent(indent)
+		f.buf.WriteString(strings.TrimPrefix(f.valueString(node, indent, nil, false), " "))
+	}
+}
+
+func (f *formatter) printMappingValue(n *ast.MappingValueNode, indent int, order map[string]int) {
+	f.printComment(n.Comment, indent)
+	f.printIndent(indent)
+	key := f.keyString(n.Key)
+	f.buf.WriteString(key)
+	f.buf.WriteString(":")
+	if c := n.Key.GetComment(); c != nil {
+		f.buf.WriteString(" ")
+		f.buf.WriteString(strings.TrimSpace(c.String()))
+	}
+	var childOrder map[string]int
+	if isScenarioOrder(order) && key == "steps" {
+		childOrder = stepKeyOrder
+	}
+	literal := f.literal
+	if (isScenarioOrder(order) || isStepOrder(order)) && literalKeys[key] {
+		f.literal = true
+	}
+	f.buf.WriteString(f.valueString(n.Value, indent, childOrder, false))
+	f.literal = literal
+}
+
+func (f *formatter) printSequence(n *ast.SequenceNode, indent int, order map[string]int) {
+	f.printComment(n.Comment, indent)
+	for i, v := range n.Values {
+		if i < len(n.ValueComments) {
+			f.printComment(n.ValueComments[i], indent)
+		}
+		if isBlockMapping(v) {
+			// print the first key on the same line with "-"
+			item := formatter{literal: f.literal}
+			item.printBlock(v, indent+formatIndent, order)
+			s := item.buf.String()
+			prefix := strings.Repeat(" ", indent+formatIndent)
+			if strings.HasPrefix(s, prefix) && !strings.HasPrefix(s, prefix+"#") {
+				f.printIndent(indent)
+				f.buf.WriteString("- ")
+				f.buf.WriteString(strings.TrimPrefix(s, prefix))
+				continue
+			}
+			f.printIndent(indent)
+			f.buf.WriteString("-\n")
+			f.buf.WriteString(s)
+			continue
+		}
+		f.printIndent(indent)
+		f.buf.WriteString("-")
+		f.buf.WriteString(f.valueString(v, indent, order, true))
+	}
+}
+
+// valueString returns the string of the value after "key:" or "-" including the line break.
+// indent is the indentation of the key or "-".
+func (f *formatter) valueString(node ast.Node, indent int, order map[string]int, inSequence bool) string {
+	switch n := node.(type) {
+	case *ast.AnchorNode:
+		return fmt.Sprintf(" &%s%s", n.Name.String(), f.valueString(n.Value, indent, order, inSequence))
+	case *ast.TagNode:
+		return fmt.Sprintf(" %s%s", n.Start.Value, f.valueString(n.Value, indent, order, inSequence))
+	case *ast.AliasNode:
+		return fmt.Sprintf(" *%s%s\n", n.Value.String(), lineComment(n))
+	case *ast.MappingNode, *ast.MappingValueNode, *ast.SequenceNode:
+		if flow, ok := flowCollection(n); ok {
+			return fmt.Sprintf(" %s%s\n", f.flowString(flow), lineComment(n))
+		}
+		child := formatter{literal: f.literal}
+		childIndent := indent + formatIndent
+		if _, ok := n.(*ast.SequenceNode); ok && !inSequence {
+			// sequences are not indented under the key
+			childIndent = indent
+		}
+		child.printBlock(n, childIndent, order)
+		return "\n" + child.buf.String()
+	case *ast.LiteralNode:
+		var b strings.Builder
+		fmt.Fprintf(&b, " %s%s\n", n.Start.Value, lineComment(n))
+		content := strings.TrimRight(n.Value.Value, "\n")
+		if strings.Contains(n.Start.Value, "+") {
+			// keep the trailing line breaks as the blank lines
+			content = strings.TrimSuffix(n.Value.Value, "\n")
+		}
+		for _, l := range strings.Split(f.normalizeTemplateSpaces(content, content), "\n") {
+			if l != "" {
+				b.WriteString(strings.Repeat(" ", indent+formatIndent))
+				b.WriteString(l)
+			}
+			b.WriteString("\n")
+		}
+		return b.String()
+	case *ast.NullNode:
+		if n.Token.Origin == "null" {
+			// implicit null like "key:"
+			return lineComment(n) + "\n"
+		}
+	}
+	return fmt.Sprintf(" %s%s\n", f.scalarString(node), lineComment(node))
+}
+
+func (f *formatter) printComment(c *ast.CommentGroupNode, indent int) {
+	if c == nil {
+		return
+	}
+	for _, l := range strings.Split(c.String(), "\n") {
+		f.printIndent(indent)
+		f.buf.WriteString(strings.TrimSpace(l))
+		f.buf.WriteString("\n")
+	}
+}
+
+func (f *formatter) printIndent(indent int) {
+	f.buf.WriteString(strings.Repeat(" ", indent))
+}
+
+func isScenarioOrder(order map[string]int) bool {
+	return order != nil && reflect.ValueOf(order).Pointer() == reflect.ValueOf(scenarioKeyOrder).Pointer()
+}
+
+func isStepOrder(order map[string]int) bool {
+	return order != nil && reflect.ValueOf(order).Pointer() == reflect.ValueOf(stepKeyOrder).Pointer()
+}
+
+func isBlockMapping(node ast.Node) bool {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		return !n.IsFlowStyle && len(n.Values) > 0
+	case *ast.MappingValueNode:
+		return true
+	}
+	return false
+}
+
+// flowCollection returns the collection node if it should be printed in the flow style.
+func flowCollection(node ast.Node) (ast.Node, bool) {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		return n, n.IsFlowStyle || len(n.Values) == 0
+	case *ast.SequenceNode:
+		return n, n.IsFlowStyle || len(n.Values) == 0
+	}
+	return nil, false
+}
+
+// sortMappingValues sorts the values by order stably.
+// The keys not in order are put after the known keys.
+func (f *formatter) sortMappingValues(values []*ast.MappingValueNode, order map[string]int) []*ast.MappingValueNode {
+	if order == nil {
+		return values
+	}
+	sorted := make([]*ast.MappingValueNode, len(values))
+	copy(sorted, values)
+	rank := func(v *ast.MappingValueNode) int {
+		if i, ok := order[f.keyString(v.Key)]; ok {
+			return i
+		}
+		return len(order)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return rank(sorted[i]) < rank(sorted[j])
+	})
+	return sorted
+}
+
+func (f *formatter) keyString(key ast.Node) string {
+	if _, ok := key.(*ast.MergeKeyNode); ok {
+		return "<<"
+	}
+	return f.scalarString(key)
+}
+
+// scalarString returns the string of the scalar node keeping its quoting style.
+func (f *formatter) scalarString(node ast.Node) string {
+	tk := node.GetToken()
+	if tk == nil {
+		return node.String()
+	}
+	raw := strings.TrimSpace(tk.Origin)
+	switch tk.Type {
+	case token.DoubleQuoteType:
+		if strings.Contains(raw, "\n") || !strings.HasPrefix(raw, `"`) {
+			raw = strconv.Quote(tk.Value)
+		}
+		return f.normalizeTemplateSpaces(raw, tk.Value)
+	case token.SingleQuoteType:
+		if strings.Contains(raw, "\n") || !strings.HasPrefix(raw, `'`) {
+			raw = fmt.Sprintf("'%s'", strings.ReplaceAll(tk.Value, "'", "''"))
+		}
+		return f.normalizeTemplateSpaces(raw, tk.Value)
+	case token.StringType:
+		return f.normalizeTemplateSpaces(tk.Value, tk.Value)
+	}
+	if raw == "" || strings.Contains(raw, "\n") {
+		return tk.Value
+	}
+	return raw
+}
+
+// flowString returns the string of the node in the flow style.
+func (f *formatter) flowString(node ast.Node) string {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		values := make([]string, 0, len(n.Values))
+		for _, v := range n.Values {
+			values = append(values, f.flowString(v))
+		}
+		return fmt.Sprintf("{%s}", strings.Join(values, ", "))
+	case *ast.MappingValueNode:
+		return fmt.Sprintf("%s: %s", f.keyString(n.Key), f.flowString(n.Value))
+	case *ast.SequenceNode:
+		values := make([]string, 0, len(n.Values))
+		for _, v := range n.Values {
+			values = append(values, f.flowString(v))
+		}
+		return fmt.Sprintf("[%s]", strings.Join(values, ", "))
+	case *ast.AnchorNode:
+		return fmt.Sprintf("&%s %s", n.Name.String(), f.flowString(n.Value))
+	case *ast.AliasNode:
+		return fmt.Sprintf("*%s", n.Value.String())
+	case *ast.TagNode:
+		return fmt.Sprintf("%s %s", n.Start.Value, f.flowString(n.Value))
+	}
+	return f.scalarString(node)
+}
+
+func lineComment(node ast.Node) string {
+	c := node.GetComment()
+	if c == nil {
+		return ""
+	}
+	return " " + strings.TrimSpace(c.String())
+}
+
+// normalizeTemplateSpaces removes the spaces around template parameters of raw which is the source of the string value.
+// The spaces are kept if the node is not evaluated as a template or value is not a valid template.
+func (f *formatter) normalizeTemplateSpaces(raw, value string) string {
+	if f.literal || !strings.Contains(value, "{{") {
+		return raw
+	}
+	if _, err := template.New(value); err != nil {
+		return raw
+	}
+	return normalizeTemplateSpaces(raw)
+}
+
+// normalizeTemplateSpaces removes the spaces around template parameters.
+func normalizeTemplateSpaces(s string) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+	s = templateLeftSpacePattern.ReplaceAllString(s, "{{")
+	return templateRightSpacePattern.ReplaceAllString(s, "}}")
+}
+
+// equivalent returns an error if formatted has the different content from src.
+func equivalent(src, formatted []byte) error {
+	expect, err := decodeAll(src)
+	if err != nil {
+		return err
+	}
+	got, err := decodeAll(formatted)
+	if err != nil {
+		return err
+	}
+	// the spaces around template parameters are removed from both not to depend on which fields are normalized
+	if !reflect.DeepEqual(normalizeValue(expect), normalizeValue(got)) {
+		return errors.New("the formatted content is different from the original")
+	}
+	return nil
+}
+
+func decodeAll(b []byte) ([]interface{}, error) {
+	f, err := parser.ParseBytes(b, 0)
+	if err != nil {
+		return nil, err
+	}
+	var docs []interface{}
+	for _, doc := range f.Docs {
+		if doc.Body == nil {
+			docs = append(docs, nil)
+			continue
+		}
+		ast.Walk(chompVisitor{}, doc.Body)
+		var v interface{}
+		if err := yaml.NodeToValue(doc.Body, &v); err != nil {
+			return nil, err
+		}
+		docs = append(docs, v)
+	}
+	return docs, nil
+}
+
+// chompVisitor applies the chomping indicators to the block scalars
+// because the parser keeps the blank lines after block scalars regardless of the chomping indicator.
+type chompVisitor struct{}
+
+// Visit implements ast.Visitor interface.
+func (v chompVisitor) Visit(node ast.Node) ast.Visitor {
+	n, ok := node.(*ast.LiteralNode)
+	if !ok {
+		return v
+	}
+	switch {
+	case strings.Contains(n.Start.Value, "+"):
+	case strings.Contains(n.Start.Value, "-"):
+		n.Value.Value = strings.TrimRight(n.Value.Value, "\n")
+	default:
+		if s := strings.TrimRight(n.Value.Value, "\n"); s != "" {
+			n.Value.Value = s + "\n"
+		}
+	}
+	return v
+}
+
+// normalizeValue normalizes the spaces around template parameters of the decoded value to compare.
+func normalizeValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return normalizeTemplateSpaces(v)
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalizeValue(e)
+		}
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalizeValue(e)
+		}
+	}
+	return v
+}
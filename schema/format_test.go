@@ -0,0 +1,161 @@
+package schema
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormat(t *testing.T) {
+	tests := map[string]struct {
+		src    string
+		expect string
+	}{
+		"order keys": {
+			src: `steps:
+- request:
+    url: http://example.com
+  protocol: http
+  title: get
+title: scenario
+`,
+			expect: `title: scenario
+steps:
+- title: get
+  protocol: http
+  request:
+    url: http://example.com
+`,
+		},
+		"indentation": {
+			src: `title:    scenario
+vars:
+    a:
+        - 1
+        - b: 2
+          c: 3
+`,
+			expect: `title: scenario
+vars:
+  a:
+  - 1
+  - b: 2
+    c: 3
+`,
+		},
+		"template spaces": {
+			src: `title: '{{  vars.name  }}'
+vars:
+  name: "{{ env.NAME }}"
+  invalid: "{{ env.NAME"
+`,
+			expect: `title: '{{  vars.name  }}'
+vars:
+  name: "{{env.NAME}}"
+  invalid: "{{ env.NAME"
+`,
+		},
+		"keep anchors before aliases": {
+			src: `steps:
+- vars: *vars
+anchors:
+  vars: &vars
+    a: 1
+`,
+			expect: `anchors:
+  vars: &vars
+    a: 1
+steps:
+- vars: *vars
+`,
+		},
+		"block scalars": {
+			src: `vars:
+  clip: |
+      a
+      b
+
+  strip: |-
+    a
+
+  keep: |+
+    a
+    b
+
+
+  last: 1
+`,
+			expect: `vars:
+  clip: |
+    a
+    b
+  strip: |-
+    a
+  keep: |+
+    a
+    b
+
+
+  last: 1
+`,
+		},
+		"null": {
+			src: `title:
+vars:
+  a: null
+`,
+			expect: `title:
+vars:
+  a: null
+`,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			got, err := Format([]byte(test.src))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(test.expect, string(got)); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFormat_File(t *testing.T) {
+	src, err := os.ReadFile("testdata/format/unformatted.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, err := os.ReadFile("testdata/format/formatted.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Format(src)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff := cmp.Diff(string(expect), string(got)); diff != "" {
+		t.Errorf("differs (-want +got):\n%s", diff)
+	}
+	got, err = Format(got)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff := cmp.Diff(string(expect), string(got)); diff != "" {
+		t.Errorf("not idempotent (-want +got):\n%s", diff)
+	}
+}
+
+func TestFormat_Error(t *testing.T) {
+	src, err := os.ReadFile("testdata/parse-error.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Format(src); err == nil {
+		t.Fatal("no error")
+	}
+}
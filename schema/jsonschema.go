@@ -0,0 +1,167 @@
+package schema
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/zoncoen/scenarigo/protocol"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+var (
+	typeMapSlice = reflect.TypeOf(yaml.MapSlice{})
+	typeRequest  = reflect.TypeOf(Request{})
+	typeExpect   = reflect.TypeOf(Expect{})
+	typeAnchors  = reflect.TypeOf(anchors{})
+)
+
+// ScenarioJSONSchema returns the JSON Schema of scenario files.
+// The shapes of request and expect are derived from the registered protocols which implement protocol.TypeProvider.
+func ScenarioJSONSchema() map[string]interface{} {
+	r := newJSONSchemaReflector()
+	root := r.reflect(reflect.TypeOf(Scenario{}))
+	step := r.definitions[definitionName(reflect.TypeOf(Step{}))].(map[string]interface{})
+	var conds []interface{}
+	for _, p := range protocol.Protocols() {
+		tp, ok := p.(protocol.TypeProvider)
+		if !ok {
+			continue
+		}
+		conds = append(conds, map[string]interface{}{
+			"if": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"protocol": map[string]interface{}{
+						"const": strings.ToLower(p.Name()),
+					},
+				},
+				"required": []string{"protocol"},
+			},
+			"then": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"request": r.reflect(tp.RequestType()),
+					"expect":  r.reflect(tp.ExpectType()),
+				},
+			},
+		})
+	}
+	if len(conds) > 0 {
+		step["allOf"] = conds
+	}
+	return r.document("scenarigo scenario", root)
+}
+
+// ConfigJSONSchema returns the JSON Schema of configuration files.
+func ConfigJSONSchema() map[string]interface{} {
+	r := newJSONSchemaReflector()
+	return r.document("scenarigo configuration", r.reflect(reflect.TypeOf(Config{})))
+}
+
+// jsonSchemaReflector builds JSON Schemas from Go types by their "yaml" struct tags.
+// Named struct types are put in the definitions and referred by $ref.
+type jsonSchemaReflector struct {
+	definitions map[string]interface{}
+}
+
+func newJSONSchemaReflector() *jsonSchemaReflector {
+	return &jsonSchemaReflector{
+		definitions: map[string]interface{}{},
+	}
+}
+
+func (r *jsonSchemaReflector) document(title string, root map[string]interface{}) map[string]interface{} {
+	doc := map[string]interface{}{
+		"$schema":     jsonSchemaDraft,
+		"title":       title,
+		"definitions": r.definitions,
+	}
+	for k, v := range root {
+		doc[k] = v
+	}
+	return doc
+}
+
+func (r *jsonSchemaReflector) reflect(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case typeMapSlice:
+		return map[string]interface{}{"type": "object"}
+	case typeRequest, typeExpect, typeAnchors:
+		// decoded by the protocol or ignored
+		return map[string]interface{}{}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		// YAML scalars like 200 can be decoded into strings
+		return map[string]interface{}{"type": []string{"string", "number"}}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": r.reflect(t.Elem()),
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": r.reflect(t.Elem()),
+		}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.reflectStruct(t)
+		}
+		name := definitionName(t)
+		if _, ok := r.definitions[name]; !ok {
+			// reserve the name before reflecting fields for recursive types
+			r.definitions[name] = nil
+			r.definitions[name] = r.reflectStruct(t)
+		}
+		return map[string]interface{}{"$ref": fmt.Sprintf("#/definitions/%s", name)}
+	}
+	// interface{} accepts any values
+	return map[string]interface{}{}
+}
+
+// reflectStruct returns the schema of the struct type.
+// The fields without "yaml" struct tags are ignored because they are not decoded from YAML.
+func (r *jsonSchemaReflector) reflectStruct(t reflect.Type) map[string]interface{} {
+	props := map[string]interface{}{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tag, ok := field.Tag.Lookup("yaml")
+		if !ok {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		props[name] = r.reflect(field.Type)
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+}
+
+func definitionName(t reflect.Type) string {
+	return fmt.Sprintf("%s.%s", path.Base(t.PkgPath()), t.Name())
+}
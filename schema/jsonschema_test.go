@@ -0,0 +1,157 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zoncoen/scenarigo/protocol"
+)
+
+type typedTestProtocol struct {
+	testProtocol
+}
+
+type typedTestRequest struct {
+	Method string `yaml:"method"`
+}
+
+type typedTestExpect struct {
+	Code int `yaml:"code"`
+}
+
+func (p *typedTestProtocol) RequestType() reflect.Type { return reflect.TypeOf(typedTestRequest{}) }
+func (p *typedTestProtocol) ExpectType() reflect.Type  { return reflect.TypeOf(typedTestExpect{}) }
+
+func TestJSONSchemaReflector(t *testing.T) {
+	type node struct {
+		Name     string  `yaml:"name"`
+		Children []*node `yaml:"children,omitempty"`
+	}
+	tests := map[string]struct {
+		v           interface{}
+		expect      map[string]interface{}
+		definitions map[string]interface{}
+	}{
+		"string": {
+			v:      "",
+			expect: map[string]interface{}{"type": []string{"string", "number"}},
+		},
+		"pointer": {
+			v:      new(int),
+			expect: map[string]interface{}{"type": "integer"},
+		},
+		"interface": {
+			v:      []interface{}{},
+			expect: map[string]interface{}{"type": "array", "items": map[string]interface{}{}},
+		},
+		"map": {
+			v: map[string]bool{},
+			expect: map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": map[string]interface{}{"type": "boolean"},
+			},
+		},
+		"anonymous struct": {
+			v: struct {
+				Name   string  `yaml:"name"`
+				Ratio  float64 `yaml:"ratio,omitempty"`
+				Ignore string  `yaml:"-"`
+				NoTag  string
+				hidden string
+			}{},
+			expect: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name":  map[string]interface{}{"type": []string{"string", "number"}},
+					"ratio": map[string]interface{}{"type": "number"},
+				},
+				"additionalProperties": false,
+			},
+		},
+		"recursive struct": {
+			v:      node{},
+			expect: map[string]interface{}{"$ref": "#/definitions/schema.node"},
+			definitions: map[string]interface{}{
+				"schema.node": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"name": map[string]interface{}{"type": []string{"string", "number"}},
+						"children": map[string]interface{}{
+							"type":  "array",
+							"items": map[string]interface{}{"$ref": "#/definitions/schema.node"},
+						},
+					},
+					"additionalProperties": false,
+				},
+			},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			r := newJSONSchemaReflector()
+			if diff := cmp.Diff(test.expect, r.reflect(reflect.TypeOf(test.v))); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+			if test.definitions == nil {
+				test.definitions = map[string]interface{}{}
+			}
+			if diff := cmp.Diff(test.definitions, r.definitions); diff != "" {
+				t.Errorf("definitions differ (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestScenarioJSONSchema(t *testing.T) {
+	p := &typedTestProtocol{testProtocol{name: "typed"}}
+	protocol.Register(p)
+	defer protocol.Unregister(p.Name())
+
+	s := ScenarioJSONSchema()
+	if got, expect := s["$ref"], "#/definitions/schema.Scenario"; got != expect {
+		t.Fatalf("expect %q but got %q", expect, got)
+	}
+	defs := s["definitions"].(map[string]interface{})
+	step := defs["schema.Step"].(map[string]interface{})
+	var cond map[string]interface{}
+	for _, c := range step["allOf"].([]interface{}) {
+		c := c.(map[string]interface{})
+		if c["if"].(map[string]interface{})["properties"].(map[string]interface{})["protocol"].(map[string]interface{})["const"] == "typed" {
+			cond = c
+		}
+	}
+	if cond == nil {
+		t.Fatal("condition for the protocol not found")
+	}
+	expect := map[string]interface{}{
+		"properties": map[string]interface{}{
+			"request": map[string]interface{}{"$ref": "#/definitions/schema.typedTestRequest"},
+			"expect":  map[string]interface{}{"$ref": "#/definitions/schema.typedTestExpect"},
+		},
+	}
+	if diff := cmp.Diff(expect, cond["then"]); diff != "" {
+		t.Errorf("differs (-want +got):\n%s", diff)
+	}
+	for _, name := range []string{"schema.typedTestRequest", "schema.typedTestExpect", "schema.RetryPolicy", "schema.WaitFor"} {
+		if _, ok := defs[name]; !ok {
+			t.Errorf("definition %s not found", name)
+		}
+	}
+}
+
+func TestConfigJSONSchema(t *testing.T) {
+	s := ConfigJSONSchema()
+	defs := s["definitions"].(map[string]interface{})
+	cfg := defs["schema.Config"].(map[string]interface{})
+	props := cfg["properties"].(map[string]interface{})
+	if _, ok := props["Root"]; ok {
+		t.Error("ignored field found")
+	}
+	for _, name := range []string{"schemaVersion", "scenarios", "pluginDirectory", "profiles", "output"} {
+		if _, ok := props[name]; !ok {
+			t.Errorf("property %s not found", name)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/parser"
+
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// StepLibrary represents reusable steps which scenarios refer by "uses".
+// The keys are the names of steps with the library name like "auth.login".
+type StepLibrary map[string]*Step
+
+// LoadStepLibrary loads step libraries from files.
+// A library file is a map of named steps, and the library name is the filename without the extension.
+// The library names must be unique across the files, and the step names must be unique in the library.
+func LoadStepLibrary(paths ...string) (StepLibrary, error) {
+	lib := StepLibrary{}
+	files := map[string]string{}
+	for _, path := range paths {
+		f, err := parser.ParseFile(path, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse step library %s: %w", path, err)
+		}
+		dir, err := filepath.Abs(filepath.Dir(path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get directory of step library %s: %w", path, err)
+		}
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if dup, ok := files[name]; ok {
+			return nil, fmt.Errorf("duplicate step library %q: %s and %s", name, dup, path)
+		}
+		files[name] = path
+		var buf bytes.Buffer
+		dec := yaml.NewDecoder(&buf, yaml.UseOrderedMap(), yaml.Strict())
+		for _, doc := range f.Docs {
+			var steps map[string]*Step
+			if err := dec.DecodeFromNode(doc.Body, &steps); err != nil {
+				return nil, fmt.Errorf("failed to decode step library %s: %w", path, err)
+			}
+			for stepName, step := range steps {
+				if step.Uses != "" {
+					return nil, errors.WithNodeAndColored(
+						errors.ErrorPathf(fmt.Sprintf("%s.uses", stepName), "uses is not available in step libraries"),
+						doc.Body,
+						false,
+					)
+				}
+				key := fmt.Sprintf("%s.%s", name, stepName)
+				if _, ok := lib[key]; ok {
+					return nil, errors.WithNodeAndColored(
+						errors.ErrorPathf(stepName, "duplicate step %q in step library %s", key, path),
+						doc.Body,
+						false,
+					)
+				}
+				if step.Include != "" && !filepath.IsAbs(step.Include) {
+					// the included file is relative to the library, not to the scenario which uses the step
+					step.Include = filepath.Join(dir, step.Include)
+				}
+				lib[key] = step
+			}
+		}
+	}
+	return lib, nil
+}
+
+// resolveSteps replaces the steps which use library steps with the library steps.
+func (l StepLibrary) resolveSteps(s *Scenario) error {
+	for i, step := range s.Steps {
+		if step.Uses == "" {
+			continue
+		}
+		if err := step.validateUses(); err != nil {
+			return errors.WithNodeAndColored(
+				errors.WithPath(err, fmt.Sprintf("steps[%d]", i)),
+				s.Node,
+				false,
+			)
+		}
+		def, ok := l[step.Uses]
+		if !ok {
+			return errors.WithNodeAndColored(
+				errors.ErrorPathf(fmt.Sprintf("steps[%d].uses", i), "step %q not found in the step libraries", step.Uses),
+				s.Node,
+				false,
+			)
+		}
+		s.Steps[i] = resolveStep(def, step)
+	}
+	return nil
+}
+
+// validateUses returns an error if the step which uses the library step has the fields which are taken from the library step.
+// The error has the path of the field relative to the step.
+func (s *Step) validateUses() error {
+	fields := []struct {
+		name      string
+		specified bool
+	}{
+		{"protocol", s.Protocol != ""},
+		{"request", s.Request.bytes != nil},
+		{"expect", s.Expect.bytes != nil},
+		{"include", s.Include != ""},
+		{"ref", s.Ref != ""},
+		{"wait", s.Wait != ""},
+		{"waitFor", s.WaitFor != nil},
+	}
+	for _, f := range fields {
+		if f.specified {
+			return errors.ErrorPathf(f.name, "%s can't be specified with uses", f.name)
+		}
+	}
+	return nil
+}
+
+// resolveStep returns the library step def called by s.
+// The arguments given by "with" are passed to def as step vars,
+// and the fields to control the step like title and bind are taken from s.
+func resolveStep(def, s *Step) *Step {
+	resolved := *def
+	resolved.Uses = s.Uses
+	if s.Title != "" {
+		resolved.Title = s.Title
+	}
+	if s.Description != "" {
+		resolved.Description = s.Description
+	}
+	if len(s.Tags) > 0 {
+		resolved.Tags = append(append([]string{}, def.Tags...), s.Tags...)
+	}
+	if s.Skip != "" {
+		resolved.Skip = s.Skip
+	}
+	resolved.Only = def.Only || s.Only
+	if s.Bind.Vars != nil {
+		resolved.Bind = s.Bind
+	}
+	if s.Retry != nil {
+		resolved.Retry = s.Retry
+	}
+	resolved.ContinueOnError = def.ContinueOnError || s.ContinueOnError
+
+	if def.Vars != nil || s.Vars != nil || s.With != nil {
+		vars := map[string]interface{}{}
+		for _, m := range []map[string]interface{}{def.Vars, s.Vars, s.With} {
+			for k, v := range m {
+				vars[k] = v
+			}
+		}
+		resolved.Vars = vars
+	}
+	return &resolved
+}
@@ -10,17 +10,40 @@ import (
 	"github.com/pkg/errors"
 )
 
+// LoadOption represents an option to load test scenarios.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	library  StepLibrary
+	filepath string
+}
+
+// WithStepLibrary returns a option which sets the step library to resolve "uses" of steps.
+func WithStepLibrary(lib StepLibrary) LoadOption {
+	return func(o *loadOptions) {
+		o.library = lib
+	}
+}
+
+// WithFilepath returns a option which sets the file path of the scenarios loaded from a reader.
+// It is used to resolve the relative paths like included files.
+func WithFilepath(path string) LoadOption {
+	return func(o *loadOptions) {
+		o.filepath = path
+	}
+}
+
 // LoadScenarios loads test scenarios from path.
-func LoadScenarios(path string) ([]*Scenario, error) {
+func LoadScenarios(path string, opts ...LoadOption) ([]*Scenario, error) {
 	f, err := parser.ParseFile(path, 0)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to parse YAML")
 	}
-	return loadScenarios(f)
+	return loadScenarios(f, opts...)
 }
 
 // LoadScenariosFromReader loads test scenarios with io.Reader.
-func LoadScenariosFromReader(r io.Reader) ([]*Scenario, error) {
+func LoadScenariosFromReader(r io.Reader, opts ...LoadOption) ([]*Scenario, error) {
 	b, err := io.ReadAll(r)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to read")
@@ -29,10 +52,14 @@ func LoadScenariosFromReader(r io.Reader) ([]*Scenario, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to parse YAML")
 	}
-	return loadScenarios(f)
+	return loadScenarios(f, opts...)
 }
 
-func loadScenarios(f *ast.File) ([]*Scenario, error) {
+func loadScenarios(f *ast.File, opts ...LoadOption) ([]*Scenario, error) {
+	var o loadOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
 	var buf bytes.Buffer
 	dec := yaml.NewDecoder(&buf, yaml.UseOrderedMap(), yaml.Strict())
 	var scenarios []*Scenario
@@ -42,7 +69,14 @@ func loadScenarios(f *ast.File) ([]*Scenario, error) {
 			return nil, errors.Wrap(err, "failed to decode YAML")
 		}
 		s.filepath = f.Name
+		if o.filepath != "" {
+			s.filepath = o.filepath
+		}
+		s.library = o.library
 		s.Node = doc.Body
+		if err := o.library.resolveSteps(&s); err != nil {
+			return nil, err
+		}
 		scenarios = append(scenarios, &s)
 	}
 	return scenarios, nil
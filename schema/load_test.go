@@ -3,6 +3,7 @@ package schema
 import (
 	"bytes"
 	"io"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -406,3 +407,109 @@ type errReader struct {
 }
 
 func (r errReader) Read(_ []byte) (int, error) { return 0, r.err }
+
+func TestLoadScenarios_StepLibrary(t *testing.T) {
+	lib, err := LoadStepLibrary("testdata/library/auth.yaml")
+	if err != nil {
+		t.Fatalf("failed to load step library: %s", err)
+	}
+	t.Run("success", func(t *testing.T) {
+		// the included file is relative to the library
+		include, err := filepath.Abs("testdata/library/login.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := LoadScenarios("testdata/valid-uses.yaml", WithStepLibrary(lib))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		expect := []*Step{
+			{
+				Title: "login",
+				Vars: map[string]interface{}{
+					"user": "alice",
+				},
+				Include: include,
+				Uses:    "auth.login",
+				Bind: Bind{
+					Vars: map[string]interface{}{
+						"token": "{{outputs.token}}",
+					},
+				},
+			},
+		}
+		if diff := cmp.Diff(expect, got[0].Steps, cmp.AllowUnexported(Request{}, Expect{})); diff != "" {
+			t.Errorf("steps differs (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("failure", func(t *testing.T) {
+		tests := map[string]struct {
+			path      string
+			expectErr string
+		}{
+			"unknown step": {
+				path:      "testdata/unknown-uses.yaml",
+				expectErr: `step "auth.logout" not found in the step libraries`,
+			},
+			"request with uses": {
+				path:      "testdata/library-uses/request.yaml",
+				expectErr: "protocol can't be specified with uses",
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				p := &testProtocol{name: "test"}
+				protocol.Register(p)
+				defer protocol.Unregister(p.Name())
+				_, err := LoadScenarios(test.path, WithStepLibrary(lib))
+				if err == nil {
+					t.Fatal("expected error but no error")
+				}
+				if !strings.Contains(err.Error(), test.expectErr) {
+					t.Errorf("expected %q but got %q", test.expectErr, err)
+				}
+			})
+		}
+	})
+}
+
+func TestLoadStepLibrary(t *testing.T) {
+	tests := map[string]struct {
+		paths     []string
+		expectErr string
+	}{
+		"success": {
+			paths: []string{"testdata/library/auth.yaml"},
+		},
+		"duplicate library": {
+			paths: []string{
+				"testdata/library-duplicate/a/auth.yaml",
+				"testdata/library-duplicate/b/auth.yaml",
+			},
+			expectErr: `duplicate step library "auth": testdata/library-duplicate/a/auth.yaml and testdata/library-duplicate/b/auth.yaml`,
+		},
+		"duplicate step": {
+			paths:     []string{"testdata/library-duplicate/steps.yaml"},
+			expectErr: `duplicate step "steps.login" in step library testdata/library-duplicate/steps.yaml`,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadStepLibrary(test.paths...)
+			if test.expectErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error but no error")
+			}
+			if !strings.Contains(err.Error(), test.expectErr) {
+				t.Errorf("expected %q but got %q", test.expectErr, err)
+			}
+		})
+	}
+}
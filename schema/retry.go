@@ -1,18 +1,31 @@
 package schema
 
 import (
-	"context"
+	gocontext "context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/lestrrat-go/backoff"
 	"golang.org/x/xerrors"
 )
 
+// Retry conditions which can be specified by the "on" field of the retry policy.
+const (
+	RetryOnRequestError   = "requestError"
+	RetryOnAssertionError = "assertionError"
+
+	// the prefixes of the conditions which match the status codes of HTTP and gRPC responses
+	RetryOnStatusPrefix = "status:"
+	RetryOnGRPCPrefix   = "grpc:"
+)
+
 // RetryPolicy represents a retry policy.
 type RetryPolicy struct {
 	Constant    *RetryPolicyConstant    `yaml:"constant"`
 	Exponential *RetryPolicyExponential `yaml:"exponential"`
+	Until       interface{}             `yaml:"until"`
+	On          []string                `yaml:"on"`
 }
 
 // Build returns p as backoff.Policy.
@@ -22,6 +35,11 @@ func (p *RetryPolicy) Build() (backoff.Policy, error) {
 		if p.Constant != nil && p.Exponential != nil {
 			return nil, xerrors.New("ambiguous retry policy")
 		}
+		for _, c := range p.On {
+			if err := validateRetryCondition(c); err != nil {
+				return nil, err
+			}
+		}
 		if p.Constant != nil {
 			return p.Constant.Build()
 		}
@@ -32,6 +50,19 @@ func (p *RetryPolicy) Build() (backoff.Policy, error) {
 	return &retryPolicyNever{}, nil
 }
 
+func validateRetryCondition(c string) error {
+	switch {
+	case c == RetryOnRequestError, c == RetryOnAssertionError:
+		return nil
+	case strings.HasPrefix(c, RetryOnStatusPrefix) && len(c) > len(RetryOnStatusPrefix):
+		return nil
+	case strings.HasPrefix(c, RetryOnGRPCPrefix) && len(c) > len(RetryOnGRPCPrefix):
+		return nil
+	default:
+		return xerrors.Errorf("unknown retry condition %q", c)
+	}
+}
+
 // RetryPolicyConstant represents a constant retry policy.
 type RetryPolicyConstant struct {
 	Interval       string  `yaml:"interval"`
@@ -111,8 +142,8 @@ func (p *RetryPolicyExponential) Build() (backoff.Policy, error) {
 type retryPolicyNever struct{}
 
 // Start implements backoff.Policy interface.
-func (*retryPolicyNever) Start(ctx context.Context) (backoff.Backoff, backoff.CancelFunc) {
-	ctx, cancel := context.WithCancel(ctx)
+func (*retryPolicyNever) Start(ctx gocontext.Context) (backoff.Backoff, backoff.CancelFunc) {
+	ctx, cancel := gocontext.WithCancel(ctx)
 	b := &retryBackoffNever{
 		ctx:    ctx,
 		cancel: cancel,
@@ -128,7 +159,7 @@ func (*retryPolicyNever) Start(ctx context.Context) (backoff.Backoff, backoff.Ca
 }
 
 type retryBackoffNever struct {
-	ctx    context.Context
+	ctx    gocontext.Context
 	cancel func()
 	next   chan struct{}
 }
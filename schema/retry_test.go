@@ -1,7 +1,7 @@
 package schema
 
 import (
-	"context"
+	gocontext "context"
 	"fmt"
 	"testing"
 	"time"
@@ -10,7 +10,7 @@ import (
 )
 
 func TestNeverBackoff(t *testing.T) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), time.Second)
 	defer cancel()
 
 	policy := &retryPolicyNever{}
@@ -75,3 +75,42 @@ func TestRetryPolicyConstant_Build(t *testing.T) {
 		}
 	})
 }
+
+func TestRetryPolicy_Build(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		tests := map[string]struct {
+			policy *RetryPolicy
+			expect string
+		}{
+			"ambiguous": {
+				policy: &RetryPolicy{
+					Constant:    &RetryPolicyConstant{Interval: "1s"},
+					Exponential: &RetryPolicyExponential{},
+				},
+				expect: "ambiguous retry policy",
+			},
+			"unknown condition": {
+				policy: &RetryPolicy{
+					On: []string{"unknown"},
+				},
+				expect: `unknown retry condition "unknown"`,
+			},
+			"empty status": {
+				policy: &RetryPolicy{
+					On: []string{"status:"},
+				},
+				expect: `unknown retry condition "status:"`,
+			},
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				if _, err := test.policy.Build(); err == nil {
+					t.Fatal("no error")
+				} else if got, expect := err.Error(), test.expect; got != expect {
+					t.Errorf("expect %q but got %q", expect, got)
+				}
+			})
+		}
+	})
+}
@@ -2,6 +2,8 @@
 package schema
 
 import (
+	"path/filepath"
+
 	"github.com/goccy/go-yaml/ast"
 	"github.com/pkg/errors"
 
@@ -12,18 +14,26 @@ import (
 
 // Scenario represents a test scenario.
 type Scenario struct {
-	Title       string                 `yaml:"title"`
-	Description string                 `yaml:"description"`
-	Plugins     map[string]string      `yaml:"plugins"`
-	Vars        map[string]interface{} `yaml:"vars"`
-	Steps       []*Step                `yaml:"steps"`
+	Title         string                 `yaml:"title"`
+	Description   string                 `yaml:"description"`
+	Tags          []string               `yaml:"tags"`
+	Skip          string                 `yaml:"skip"`
+	Only          bool                   `yaml:"only"`
+	ExpectFailure string                 `yaml:"expectFailure"`
+	Soft          bool                   `yaml:"soft"`
+	Retry         *RetryPolicy           `yaml:"retry"`
+	Plugins       map[string]string      `yaml:"plugins"`
+	Vars          map[string]interface{} `yaml:"vars"`
+	Steps         []*Step                `yaml:"steps"`
+	Outputs       map[string]interface{} `yaml:"outputs"`
 
 	// The strict YAML decoder fails to decode if finds an unknown field.
 	// Anchors is the field for enabling to define YAML anchors by avoiding the error.
 	// This field doesn't need to hold some data because anchors expand by the decoder.
 	Anchors anchors `yaml:"anchors"`
 
-	filepath string // YAML filepath
+	filepath string      // YAML filepath
+	library  StepLibrary // step library to resolve "uses"
 	Node     ast.Node
 }
 
@@ -32,18 +42,60 @@ func (s *Scenario) Filepath() string {
 	return s.filepath
 }
 
+// IncludePath returns the path of the file included by the step of s.
+// The relative path is resolved from the directory of s, and the absolute path like the one rewritten by the step library is returned as is.
+func (s *Scenario) IncludePath(include string) string {
+	if filepath.IsAbs(include) {
+		return include
+	}
+	return filepath.Join(filepath.Dir(s.filepath), include)
+}
+
+// StepLibrary returns the step library which is used to load s.
+func (s *Scenario) StepLibrary() StepLibrary {
+	return s.library
+}
+
+// AllTags returns the tags of s and its steps without duplicates.
+func (s *Scenario) AllTags() []string {
+	seen := map[string]struct{}{}
+	var tags []string
+	add := func(ts []string) {
+		for _, t := range ts {
+			if _, ok := seen[t]; ok {
+				continue
+			}
+			seen[t] = struct{}{}
+			tags = append(tags, t)
+		}
+	}
+	add(s.Tags)
+	for _, step := range s.Steps {
+		add(step.Tags)
+	}
+	return tags
+}
+
 // Step represents a step of scenario.
 type Step struct {
-	Title       string                 `yaml:"title"`
-	Description string                 `yaml:"description"`
-	Vars        map[string]interface{} `yaml:"vars"`
-	Protocol    string                 `yaml:"protocol"`
-	Request     Request                `yaml:"request"`
-	Expect      Expect                 `yaml:"expect"`
-	Include     string                 `yaml:"include"`
-	Ref         string                 `yaml:"ref"`
-	Bind        Bind                   `yaml:"bind"`
-	Retry       *RetryPolicy           `yaml:"retry"`
+	Title           string                 `yaml:"title"`
+	Description     string                 `yaml:"description"`
+	Tags            []string               `yaml:"tags"`
+	Skip            string                 `yaml:"skip"`
+	Only            bool                   `yaml:"only"`
+	Vars            map[string]interface{} `yaml:"vars"`
+	Protocol        string                 `yaml:"protocol"`
+	Request         Request                `yaml:"request"`
+	Expect          Expect                 `yaml:"expect"`
+	Include         string                 `yaml:"include"`
+	With            map[string]interface{} `yaml:"with"`
+	Ref             string                 `yaml:"ref"`
+	Uses            string                 `yaml:"uses"`
+	Bind            Bind                   `yaml:"bind"`
+	Wait            string                 `yaml:"wait"`
+	WaitFor         *WaitFor               `yaml:"waitFor"`
+	Retry           *RetryPolicy           `yaml:"retry"`
+	ContinueOnError bool                   `yaml:"continueOnError"`
 }
 
 type stepUnmarshaller Step
@@ -0,0 +1,149 @@
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/template"
+)
+
+// Validate validates s statically without running it and returns all errors found.
+// It checks the retry policies, the template strings, and the existence of the included files and the plugins.
+// pluginDir is the root directory of plugins, and the relative paths of plugins are resolved from the current directory if it is empty.
+// The errors have the paths of the invalid fields, so they can be printed with the YAML source positions by errors.WithNodeAndColored.
+func (s *Scenario) Validate(pluginDir string) []error {
+	var errs []error
+	if s.Node != nil {
+		errs = append(errs, validateTemplates(s.Node, "")...)
+	}
+	if s.Retry != nil {
+		if s.Retry.Until != nil || len(s.Retry.On) > 0 {
+			errs = append(errs, errors.ErrorPath("retry", "until and on are not available for scenarios"))
+		}
+		if _, err := s.Retry.Build(); err != nil {
+			errs = append(errs, errors.WrapPath(err, "retry", "invalid retry policy"))
+		}
+	}
+	names := make([]string, 0, len(s.Plugins))
+	for name := range s.Plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := s.Plugins[name]
+		if pluginDir != "" {
+			path = filepath.Join(pluginDir, path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			errs = append(errs, errors.ErrorPathf(fmt.Sprintf("plugins.%s", name), "plugin %s not found", s.Plugins[name]))
+		}
+	}
+	for i, step := range s.Steps {
+		errs = append(errs, s.validateStep(step, i)...)
+	}
+	return errs
+}
+
+func (s *Scenario) validateStep(step *Step, idx int) []error {
+	var errs []error
+	path := fmt.Sprintf("steps[%d]", idx)
+	if step.Retry != nil {
+		if _, err := step.Retry.Build(); err != nil {
+			errs = append(errs, errors.WrapPath(err, path+".retry", "invalid retry policy"))
+		}
+	}
+	if step.Wait != "" && !strings.Contains(step.Wait, "{{") {
+		if _, err := time.ParseDuration(step.Wait); err != nil {
+			errs = append(errs, errors.WrapPath(err, path+".wait", "invalid wait"))
+		}
+	}
+	if err := step.ValidateWait(); err != nil {
+		errs = append(errs, errors.WithPath(err, path))
+	}
+	if step.WaitFor != nil {
+		if step.WaitFor.Condition == nil && step.Request.Invoker == nil {
+			errs = append(errs, errors.ErrorPath(path+".waitFor", "condition or request must be specified"))
+		}
+		if _, err := step.WaitFor.RetryPolicy().Build(); err != nil {
+			errs = append(errs, errors.WrapPath(err, path+".waitFor", "invalid waitFor policy"))
+		}
+	}
+	if step.Include != "" && s.filepath != "" {
+		if _, err := os.Stat(s.IncludePath(step.Include)); err != nil {
+			errs = append(errs, errors.ErrorPathf(path+".include", "included file %s not found", step.Include))
+		}
+	}
+	return errs
+}
+
+// ValidateStepLibrary parses all template strings in the steps of the step library file statically.
+// The library steps are validated once here because the scenarios which use them have no YAML nodes of them.
+// The errors have the YAML source positions of the invalid fields.
+func ValidateStepLibrary(path string, colored bool) []error {
+	f, err := parser.ParseFile(path, 0)
+	if err != nil {
+		return []error{fmt.Errorf("failed to parse step library %s: %w", path, err)}
+	}
+	var errs []error
+	for _, doc := range f.Docs {
+		for _, err := range validateTemplates(doc.Body, "") {
+			errs = append(errs, errors.WithNodeAndColored(err, doc.Body, colored))
+		}
+	}
+	return errs
+}
+
+// non-template fields which are not executed as templates
+var nonTemplateFields = map[string]struct{}{
+	"title":       {},
+	"description": {},
+}
+
+// validateTemplates parses all template strings in node.
+func validateTemplates(node ast.Node, path string) []error {
+	var errs []error
+	walkStrings(node, path, func(path, str string) {
+		if _, err := template.New(str); err != nil {
+			errs = append(errs, errors.WrapPath(err, path, "invalid template"))
+		}
+	})
+	return errs
+}
+
+// walkStrings calls f for each string value in node with its path except the non-template fields.
+func walkStrings(node ast.Node, path string, f func(path, str string)) {
+	switch n := node.(type) {
+	case *ast.DocumentNode:
+		walkStrings(n.Body, path, f)
+	case *ast.MappingNode:
+		for _, v := range n.Values {
+			walkStrings(v, path, f)
+		}
+	case *ast.MappingValueNode:
+		key := n.Key.String()
+		if _, ok := nonTemplateFields[key]; ok {
+			return
+		}
+		walkStrings(n.Value, fmt.Sprintf("%s.%s", path, key), f)
+	case *ast.SequenceNode:
+		for i, v := range n.Values {
+			walkStrings(v, fmt.Sprintf("%s[%d]", path, i), f)
+		}
+	case *ast.AnchorNode:
+		walkStrings(n.Value, path, f)
+	case *ast.TagNode:
+		walkStrings(n.Value, path, f)
+	case *ast.LiteralNode:
+		walkStrings(n.Value, path, f)
+	case *ast.StringNode:
+		f(strings.TrimPrefix(path, "."), n.Value)
+	}
+}
@@ -0,0 +1,81 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zoncoen/scenarigo/protocol"
+)
+
+func TestScenario_Validate(t *testing.T) {
+	tests := map[string]struct {
+		path   string
+		expect []string
+	}{
+		"valid": {
+			path: "testdata/valid.yaml",
+		},
+		"invalid": {
+			path: "testdata/invalid-lint.yaml",
+			expect: []string{
+				`.steps[0].request.url: invalid template: failed to parse "{{env.TEST_ADDR": col 16: expected 'rdbrace', found 'EOF'`,
+				`.plugins.plugin: plugin not-found.so not found`,
+				`.steps[1].wait: invalid wait: time: unknown unit " second" in duration "1 second"`,
+				`.steps[2].include: included file not-found.yaml not found`,
+				`.steps[3].retry: invalid retry policy: failed to parse interval: time: unknown unit " second" in duration "1 second"`,
+			},
+		},
+		"invalid wait": {
+			path: "testdata/invalid-wait.yaml",
+			expect: []string{
+				`.steps[0].request: request can't be specified with wait`,
+				`.steps[1].waitFor: waitFor can't be specified with wait`,
+				`.steps[2].retry: retry can't be specified with waitFor`,
+				`.steps[3].include: include can't be specified with waitFor`,
+			},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			p := &testProtocol{name: "test"}
+			protocol.Register(p)
+			defer protocol.Unregister(p.Name())
+			scns, err := LoadScenarios(test.path)
+			if err != nil {
+				t.Fatalf("failed to load scenarios: %s", err)
+			}
+			var got []string
+			for _, scn := range scns {
+				for _, err := range scn.Validate("") {
+					got = append(got, err.Error())
+				}
+			}
+			if diff := cmp.Diff(test.expect, got); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateStepLibrary(t *testing.T) {
+	p := &testProtocol{name: "test"}
+	protocol.Register(p)
+	defer protocol.Unregister(p.Name())
+	if errs := ValidateStepLibrary("testdata/library/auth.yaml", false); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	errs := ValidateStepLibrary("testdata/library-invalid/auth.yaml", false)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error but got %v", errs)
+	}
+	for _, expect := range []string{
+		`>  5 |     url: "{{env.TEST_ADDR"`,
+		`invalid template: failed to parse "{{env.TEST_ADDR": col 16: expected 'rdbrace', found 'EOF'`,
+	} {
+		if got := errs[0].Error(); !strings.Contains(got, expect) {
+			t.Errorf("error does not contain %q:\n%s", expect, got)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/goccy/go-yaml/ast"
+
+	"github.com/zoncoen/scenarigo/errors"
+	"github.com/zoncoen/scenarigo/template"
+)
+
+// UndefinedVars checks the data flow of variables statically and returns the errors of the variables which are referred but never defined.
+// It walks the steps in order and tracks the variables defined by "vars" of the scenario and steps and "bind.vars" of steps.
+// The variables and the outputs of the scenario included without "with" are available only in "bind" of the include step like on running,
+// so the references to "outputs" in the other fields are also reported.
+// defined is the names of the variables defined outside of the scenario like the global variables.
+// The errors have the paths of the templates, so they can be printed with the YAML source positions by errors.WithNodeAndColored.
+func (s *Scenario) UndefinedVars(defined ...string) []error {
+	var errs []error
+	scope := varsScope{}.with(defined...)
+	errs = append(errs, checkVars(mappingValue(s.Node, "vars"), "vars", scope, false)...)
+	scope = scope.with(varNames(s.Vars)...)
+
+	var steps []ast.Node
+	if seq, ok := mappingValue(s.Node, "steps").(*ast.SequenceNode); ok {
+		steps = seq.Values
+	}
+	for i, step := range s.Steps {
+		var node ast.Node
+		if i < len(steps) {
+			node = steps[i]
+		}
+		path := fmt.Sprintf("steps[%d]", i)
+
+		// step vars and arguments are evaluated before the step
+		errs = append(errs, checkVars(mappingValue(node, "vars"), path+".vars", scope, false)...)
+		errs = append(errs, checkVars(mappingValue(node, "with"), path+".with", scope, false)...)
+		stepScope := scope.with(varNames(step.Vars)...)
+		for _, v := range mappingValues(node) {
+			switch key := v.Key.String(); key {
+			case "vars", "with", "bind":
+			default:
+				errs = append(errs, checkVars(v, path, stepScope, false)...)
+			}
+		}
+
+		// the included scenario and the plugin step return the outputs to the bind of the step
+		outputs := step.Include != "" || step.Ref != ""
+		checkBind := true
+		if step.Include != "" && step.With == nil {
+			// the variables of the included scenario are available only in the bind of the step
+			names, ok := s.includedVars(step.Include, map[string]struct{}{})
+			if ok {
+				stepScope = stepScope.with(names...)
+			}
+			// can't check the bind because the included variables are unknown
+			checkBind = ok
+		}
+		if checkBind {
+			errs = append(errs, checkVars(mappingValue(node, "bind"), path+".bind", stepScope, outputs)...)
+		}
+		scope = scope.with(varNames(step.Bind.Vars)...)
+	}
+
+	errs = append(errs, checkVars(mappingValue(s.Node, "outputs"), "outputs", scope, false)...)
+	return errs
+}
+
+// DefinedVars returns the names of the variables which can be referred in the step of index idx.
+// If idx is equal to the number of steps, it returns the names which can be referred in outputs.
+// The variables defined outside of the scenario like the global variables are not included.
+func (s *Scenario) DefinedVars(idx int) []string {
+	scope := varsScope{}.with(varNames(s.Vars)...)
+	for i, step := range s.Steps {
+		if i == idx {
+			return scope.with(varNames(step.Vars)...).names()
+		}
+		scope = scope.with(varNames(step.Bind.Vars)...)
+	}
+	return scope.names()
+}
+
+// IncludeScopes returns the names of the variables which the files included by the steps can refer to.
+// The files included with "with" can refer to the inputs, and the others can refer to the variables of the caller at the include step.
+// The keys are the paths of the included files, and the names of the files included multiple times are merged.
+// They can be passed to UndefinedVars of the included scenarios.
+func (s *Scenario) IncludeScopes() map[string][]string {
+	scopes := map[string][]string{}
+	for i, step := range s.Steps {
+		if step.Include == "" {
+			continue
+		}
+		path := s.IncludePath(step.Include)
+		names := varNames(step.With)
+		if step.With == nil {
+			names = s.DefinedVars(i)
+		}
+		scopes[path] = varsScope{}.with(scopes[path]...).with(names...).names()
+	}
+	return scopes
+}
+
+// includedVars returns the names of the variables defined by the included scenarios.
+// It reports false if the included file can't be loaded.
+func (s *Scenario) includedVars(include string, visited map[string]struct{}) ([]string, bool) {
+	path := s.IncludePath(include)
+	if _, ok := visited[path]; ok {
+		return nil, false
+	}
+	visited[path] = struct{}{}
+	scns, err := LoadScenarios(path, WithStepLibrary(s.library))
+	if err != nil {
+		return nil, false
+	}
+	var names []string
+	for _, scn := range scns {
+		names = append(names, varNames(scn.Vars)...)
+		for _, step := range scn.Steps {
+			if step.Include != "" && step.With == nil {
+				included, ok := scn.includedVars(step.Include, visited)
+				if !ok {
+					return nil, false
+				}
+				names = append(names, included...)
+			}
+			names = append(names, varNames(step.Bind.Vars)...)
+		}
+	}
+	return names, true
+}
+
+// checkVars returns the errors of the variables referred by the templates in node which are not in scope.
+// If outputs is false, the references to "outputs" are also reported.
+func checkVars(node ast.Node, path string, scope varsScope, outputs bool) []error {
+	var errs []error
+	walkStrings(node, path, func(path, str string) {
+		tmpl, err := template.New(str)
+		if err != nil {
+			// invalid templates are reported by Validate
+			return
+		}
+		for _, name := range tmpl.Refs("vars") {
+			if _, ok := scope[name]; !ok {
+				errs = append(errs, errors.ErrorPathf(path, "variable %q is not defined", name))
+			}
+		}
+		if !outputs {
+			for _, name := range tmpl.Refs("outputs") {
+				errs = append(errs, errors.ErrorPathf(path, "output %q is available only in bind of the include step", name))
+			}
+		}
+	})
+	return errs
+}
+
+// varsScope represents the names of the defined variables.
+type varsScope map[string]struct{}
+
+// with returns a new scope which has the names in addition.
+func (s varsScope) with(names ...string) varsScope {
+	scope := make(varsScope, len(s)+len(names))
+	for name := range s {
+		scope[name] = struct{}{}
+	}
+	for _, name := range names {
+		scope[name] = struct{}{}
+	}
+	return scope
+}
+
+// names returns the sorted names in the scope.
+func (s varsScope) names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func varNames(vars map[string]interface{}) []string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	return names
+}
+
+// mappingValues returns the key-value pairs of the mapping node.
+func mappingValues(node ast.Node) []*ast.MappingValueNode {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		return n.Values
+	case *ast.MappingValueNode:
+		return []*ast.MappingValueNode{n}
+	case *ast.AnchorNode:
+		return mappingValues(n.Value)
+	case *ast.TagNode:
+		return mappingValues(n.Value)
+	}
+	return nil
+}
+
+// mappingValue returns the value of key in the mapping node.
+func mappingValue(node ast.Node, key string) ast.Node {
+	for _, v := range mappingValues(node) {
+		if v.Key.String() == key {
+			return v.Value
+		}
+	}
+	return nil
+}
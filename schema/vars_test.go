@@ -0,0 +1,156 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zoncoen/scenarigo/protocol"
+)
+
+func TestScenario_UndefinedVars(t *testing.T) {
+	tests := map[string]struct {
+		path    string
+		defined []string
+		expect  []string
+	}{
+		"valid": {
+			path: "testdata/valid.yaml",
+		},
+		"undefined": {
+			path: "testdata/vars/undefined.yaml",
+			expect: []string{
+				`.vars.user: variable "global" is not defined`,
+				`.vars.self: variable "user" is not defined`,
+				`.steps[0].vars.password: variable "password" is not defined`,
+				`.steps[0].request.body.token: variable "token" is not defined`,
+				`.steps[2].request.body.session: variable "session" is not defined`,
+				`.steps[2].request.body.unknown: variable "unknown" is not defined`,
+				`.steps[2].request.body.output: output "session" is available only in bind of the include step`,
+				`.outputs.message: variable "message" is not defined`,
+			},
+		},
+		"defined outside the scenario": {
+			path:    "testdata/vars/undefined.yaml",
+			defined: []string{"global", "unknown", "message"},
+			expect: []string{
+				`.vars.self: variable "user" is not defined`,
+				`.steps[0].vars.password: variable "password" is not defined`,
+				`.steps[0].request.body.token: variable "token" is not defined`,
+				`.steps[2].request.body.session: variable "session" is not defined`,
+				`.steps[2].request.body.output: output "session" is available only in bind of the include step`,
+			},
+		},
+		"index": {
+			path: "testdata/vars/index.yaml",
+			expect: []string{
+				`.steps[0].request.body.unknown: variable "unknown" is not defined`,
+			},
+		},
+		"included with inputs": {
+			path:    "testdata/vars/inputs.yaml",
+			defined: []string{"name", "password", "token"},
+		},
+		"included without inputs": {
+			path: "testdata/vars/inputs.yaml",
+			expect: []string{
+				`.steps[0].request.body.name: variable "name" is not defined`,
+				`.steps[0].request.body.password: variable "password" is not defined`,
+			},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			p := &testProtocol{name: "test"}
+			protocol.Register(p)
+			defer protocol.Unregister(p.Name())
+			scns, err := LoadScenarios(test.path)
+			if err != nil {
+				t.Fatalf("failed to load scenarios: %s", err)
+			}
+			var got []string
+			for _, scn := range scns {
+				for _, err := range scn.UndefinedVars(test.defined...) {
+					got = append(got, err.Error())
+				}
+			}
+			if diff := cmp.Diff(test.expect, got); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestScenario_DefinedVars(t *testing.T) {
+	p := &testProtocol{name: "test"}
+	protocol.Register(p)
+	defer protocol.Unregister(p.Name())
+	scns, err := LoadScenarios("testdata/vars/undefined.yaml")
+	if err != nil {
+		t.Fatalf("failed to load scenarios: %s", err)
+	}
+	tests := map[string]struct {
+		idx    int
+		expect []string
+	}{
+		"first step": {
+			idx:    0,
+			expect: []string{"password", "self", "user"},
+		},
+		"after bind": {
+			idx:    1,
+			expect: []string{"self", "token", "user"},
+		},
+		"after include": {
+			idx:    2,
+			expect: []string{"included", "self", "token", "user"},
+		},
+		"outputs": {
+			idx:    3,
+			expect: []string{"included", "self", "token", "user"},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(test.expect, scns[0].DefinedVars(test.idx)); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestScenario_IncludeScopes(t *testing.T) {
+	p := &testProtocol{name: "test"}
+	protocol.Register(p)
+	defer protocol.Unregister(p.Name())
+	tests := map[string]struct {
+		path   string
+		expect map[string][]string
+	}{
+		"with inputs": {
+			path: "testdata/vars/include-with.yaml",
+			expect: map[string][]string{
+				"testdata/vars/inputs.yaml": {"name", "password", "token"},
+			},
+		},
+		"without inputs": {
+			path: "testdata/vars/undefined.yaml",
+			expect: map[string][]string{
+				"testdata/vars/included.yaml": {"self", "token", "user"},
+			},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			scns, err := LoadScenarios(test.path)
+			if err != nil {
+				t.Fatalf("failed to load scenarios: %s", err)
+			}
+			if diff := cmp.Diff(test.expect, scns[0].IncludeScopes()); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package schema
+
+import (
+	"github.com/zoncoen/scenarigo/errors"
+)
+
+// default polling policy of waitFor
+const (
+	defaultWaitForInterval       = "1s"
+	defaultWaitForMaxElapsedTime = "1m"
+)
+
+// WaitFor represents a condition to wait for.
+// The condition is polled with the backoff policy until it is satisfied.
+// If the step has a request, the request is also polled until the response satisfies the expect.
+type WaitFor struct {
+	Condition   interface{}             `yaml:"condition"`
+	Constant    *RetryPolicyConstant    `yaml:"constant"`
+	Exponential *RetryPolicyExponential `yaml:"exponential"`
+}
+
+// RetryPolicy returns the retry policy to poll the condition.
+// If no backoff policy is specified, it polls every second for up to a minute.
+func (w *WaitFor) RetryPolicy() *RetryPolicy {
+	p := &RetryPolicy{
+		Constant:    w.Constant,
+		Exponential: w.Exponential,
+		Until:       w.Condition,
+	}
+	if p.Constant == nil && p.Exponential == nil {
+		maxElapsedTime := defaultWaitForMaxElapsedTime
+		p.Constant = &RetryPolicyConstant{
+			Interval:       defaultWaitForInterval,
+			MaxElapsedTime: &maxElapsedTime,
+		}
+	}
+	return p
+}
+
+// ValidateWait returns an error if the step has the fields which are ignored by "wait" or "waitFor".
+// "wait" only sleeps, so it can't have a request, an expect, waitFor, or retry.
+// "waitFor" polls the request with its own policy, so it can't have retry.
+// Both can't be used with include and ref because they take precedence.
+// The error has the path of the ignored field relative to the step.
+func (s *Step) ValidateWait() error {
+	var key string
+	switch {
+	case s.Wait != "":
+		key = "wait"
+	case s.WaitFor != nil:
+		key = "waitFor"
+	default:
+		return nil
+	}
+	fields := []struct {
+		name      string
+		specified bool
+	}{
+		{"include", s.Include != ""},
+		{"ref", s.Ref != ""},
+		{"waitFor", key == "wait" && s.WaitFor != nil},
+		{"request", key == "wait" && s.Request.bytes != nil},
+		{"expect", key == "wait" && s.Expect.bytes != nil},
+		{"retry", s.Retry != nil},
+	}
+	for _, f := range fields {
+		if f.specified {
+			return errors.ErrorPathf(f.name, "%s can't be specified with %s", f.name, key)
+		}
+	}
+	return nil
+}
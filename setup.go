@@ -0,0 +1,83 @@
+package scenarigo
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+// WithSetup returns a option which runs the scenario files before running the scenarios.
+// The variables bound by the steps of the setup scenarios become read-only global variables of all scenarios.
+// If the setup fails, no scenario runs.
+func WithSetup(paths ...string) func(*Runner) error {
+	return func(r *Runner) error {
+		files, err := getHookFiles(paths)
+		if err != nil {
+			return fmt.Errorf("failed to find setup scenarios: %w", err)
+		}
+		r.setupFiles = files
+		return nil
+	}
+}
+
+// WithTeardown returns a option which runs the scenario files after running the scenarios.
+// The teardown scenarios run even if the setup or the scenarios fail, and they can use the variables bound by the setup.
+func WithTeardown(paths ...string) func(*Runner) error {
+	return func(r *Runner) error {
+		files, err := getHookFiles(paths)
+		if err != nil {
+			return fmt.Errorf("failed to find teardown scenarios: %w", err)
+		}
+		r.teardownFiles = files
+		return nil
+	}
+}
+
+func getHookFiles(paths []string) ([]string, error) {
+	abs := make([]string, len(paths))
+	for i, path := range paths {
+		p, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		abs[i] = p
+	}
+	return getAllFiles(abs...)
+}
+
+// runHooks runs the scenarios of files sequentially and returns the variables bound by the steps.
+// The scenarios can use the variables bound by the previous ones.
+// It stops at the first failure and reports whether all scenarios passed.
+func (r *Runner) runHooks(ctx *context.Context, files []string) (map[string]interface{}, bool) {
+	vars := &keptVars{vars: map[string]interface{}{}}
+	for _, f := range files {
+		f := f
+		testName, err := filepath.Rel(r.rootDir, f)
+		if err != nil {
+			testName = f
+		}
+		ok := ctx.Run(testName, func(ctx *context.Context) {
+			scns, err := schema.LoadScenarios(f, schema.WithStepLibrary(r.stepLibrary))
+			if err != nil {
+				ctx.Reporter().Fatalf("failed to load scenarios: %s", err)
+			}
+			ctx = r.withPlugins(ctx)
+			for _, scn := range scns {
+				scn := scn
+				ok := ctx.WithNode(scn.Node).Run(scn.Title, func(ctx *context.Context) {
+					scnCtx := RunScenario(ctx.WithVars(vars.get()).WithGlobalVars(), scn)
+					vars.keep(scnCtx, scn)
+				})
+				if !ok {
+					ctx.Reporter().FailNow()
+				}
+			}
+		})
+		if !ok {
+			return vars.get(), false
+		}
+	}
+	return vars.get(), true
+}
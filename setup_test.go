@@ -0,0 +1,152 @@
+package scenarigo
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/plugin"
+	"github.com/zoncoen/scenarigo/reporter"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+func TestRunner_Setup(t *testing.T) {
+	yml := `
+title: scenario
+steps:
+- title: use token
+  ref: '{{plugins.record}}'
+`
+	tests := map[string]struct {
+		yaml         string
+		setup        []string
+		expectEvents []string
+		expectOK     bool
+		expectOutput string
+	}{
+		"setup": {
+			yaml:  yml,
+			setup: []string{"testdata/setup/login.yaml"},
+			expectEvents: []string{
+				"use token: token",
+				"logout: token",
+			},
+			expectOK: true,
+		},
+		"setup uses previous setup": {
+			yaml:  yml,
+			setup: []string{"testdata/setup/login.yaml", "testdata/setup/check.yaml"},
+			expectEvents: []string{
+				"check token: token",
+				"use token: token",
+				"logout: token",
+			},
+			expectOK: true,
+		},
+		"include with inputs": {
+			yaml: `
+title: scenario
+steps:
+- title: include
+  include: testdata/setup/include.yaml
+  with:
+    name: alice
+`,
+			setup: []string{"testdata/setup/login.yaml"},
+			expectEvents: []string{
+				"included: token",
+				"logout: token",
+			},
+			expectOK: true,
+		},
+		"setup failed": {
+			yaml:  yml,
+			setup: []string{"testdata/setup/fail.yaml", "testdata/setup/login.yaml"},
+			expectEvents: []string{
+				"logout: <nil>",
+			},
+		},
+		"override setup variable": {
+			yaml: `
+title: scenario
+steps:
+- title: use token
+  vars:
+    token: overridden
+  ref: '{{plugins.record}}'
+`,
+			setup: []string{"testdata/setup/login.yaml"},
+			expectEvents: []string{
+				"logout: token",
+			},
+			expectOutput: `variable "token" is bound by the setup scenarios and can't be overridden`,
+		},
+		"bind setup variable": {
+			yaml: `
+title: scenario
+steps:
+- title: bind token
+  ref: '{{plugins.record}}'
+  bind:
+    vars:
+      token: overridden
+`,
+			setup: []string{"testdata/setup/login.yaml"},
+			expectEvents: []string{
+				"bind token: token",
+				"logout: token",
+			},
+			expectOutput: `variable "token" is bound by the setup scenarios and can't be overridden`,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			runner, err := NewRunner(
+				WithScenariosFromReader(strings.NewReader(test.yaml)),
+				WithSetup(test.setup...),
+				WithTeardown("testdata/setup/logout.yaml"),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var (
+				m      sync.Mutex
+				events []string
+				b      bytes.Buffer
+			)
+			ok := reporter.Run(func(rptr reporter.Reporter) {
+				runner.Run(context.New(rptr).WithPlugins(map[string]interface{}{
+					"login": plugin.StepFunc(func(ctx *context.Context, step *schema.Step) *context.Context {
+						return ctx.WithResponse(map[string]string{"token": "token"})
+					}),
+					"fail": plugin.StepFunc(func(ctx *context.Context, step *schema.Step) *context.Context {
+						ctx.Reporter().FailNow()
+						return ctx
+					}),
+					"record": plugin.StepFunc(func(ctx *context.Context, step *schema.Step) *context.Context {
+						token, _ := ctx.Vars().ExtractByKey("token")
+						m.Lock()
+						defer m.Unlock()
+						events = append(events, fmt.Sprintf("%s: %v", step.Title, token))
+						return ctx
+					}),
+				}))
+			}, reporter.WithWriter(&b))
+			if ok != test.expectOK {
+				t.Errorf("expect %t but got %t\n%s", test.expectOK, ok, b.String())
+			}
+			if diff := cmp.Diff(test.expectEvents, events); diff != "" {
+				t.Errorf("differs (-want +got):\n%s\n%s", diff, b.String())
+			}
+			if !strings.Contains(b.String(), test.expectOutput) {
+				t.Errorf("output does not contain %q:\n%s", test.expectOutput, b.String())
+			}
+		})
+	}
+}
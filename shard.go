@@ -0,0 +1,135 @@
+package scenarigo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/zoncoen/scenarigo/reporter"
+)
+
+// WithShard returns a option which runs only the index-th shard of the scenario files divided into total shards.
+// index is one-based like "--shard 1/8".
+// The files are partitioned deterministically by their paths, so the shards run every file exactly once.
+// The scenarios read by WithScenariosFromReader are also divided by the order of the readers.
+func WithShard(index, total int) func(*Runner) error {
+	return func(r *Runner) error {
+		if total < 1 || index < 1 || index > total {
+			return fmt.Errorf("invalid shard %d/%d", index, total)
+		}
+		r.shardIndex = index
+		r.shardTotal = total
+		return nil
+	}
+}
+
+// WithShardReport returns a option which balances the shards by the durations of the files in the JSON test report.
+// The files which are not in the report are assumed to take the average duration.
+func WithShardReport(path string) func(*Runner) error {
+	return func(r *Runner) error {
+		if path == "" {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to read test report: %w", err)
+		}
+		defer f.Close()
+		var report reporter.TestReport
+		if err := json.NewDecoder(f).Decode(&report); err != nil {
+			return fmt.Errorf("failed to read test report: %w", err)
+		}
+		durations := map[string]time.Duration{}
+		for _, file := range report.Files {
+			durations[file.Name] += time.Duration(file.Duration)
+		}
+		r.shardDurations = durations
+		return nil
+	}
+}
+
+// shardFiles returns the files of the shard to run.
+func (r *Runner) shardFiles(files []string) []string {
+	if r.shardTotal <= 1 {
+		return files
+	}
+
+	names := make([]string, len(files))
+	for i, f := range files {
+		name, err := filepath.Rel(r.rootDir, f)
+		if err != nil {
+			name = f
+		}
+		names[i] = filepath.ToSlash(name)
+	}
+	idx := make([]int, len(files))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return names[idx[i]] < names[idx[j]]
+	})
+
+	shards := make([]int, len(files))
+	if len(r.shardDurations) == 0 {
+		for i, fi := range idx {
+			shards[fi] = i % r.shardTotal
+		}
+	} else {
+		// assign the longest files first to the shard which has the least total duration
+		durations := make([]time.Duration, len(files))
+		var (
+			sum   time.Duration
+			known int
+		)
+		for i, name := range names {
+			if d, ok := r.shardDurations[name]; ok {
+				durations[i] = d
+				sum += d
+				known++
+			} else {
+				durations[i] = -1
+			}
+		}
+		var avg time.Duration
+		if known > 0 {
+			avg = sum / time.Duration(known)
+		}
+		for i, d := range durations {
+			if d < 0 {
+				durations[i] = avg
+			}
+		}
+		sort.SliceStable(idx, func(i, j int) bool {
+			return durations[idx[i]] > durations[idx[j]]
+		})
+		totals := make([]time.Duration, r.shardTotal)
+		for _, fi := range idx {
+			min := 0
+			for s := 1; s < r.shardTotal; s++ {
+				if totals[s] < totals[min] {
+					min = s
+				}
+			}
+			shards[fi] = min
+			totals[min] += durations[fi]
+		}
+	}
+
+	filtered := []string{}
+	for i, f := range files {
+		if shards[i] == r.shardIndex-1 {
+			filtered = append(filtered, f)
+		}
+	}
+	return filtered
+}
+
+// readerInShard reports whether the i-th scenario reader belongs to the shard to run.
+// The readers have no paths, so they are assigned to the shards in turn by their indexes.
+func (r *Runner) readerInShard(i int) bool {
+	return r.shardTotal <= 1 || i%r.shardTotal == r.shardIndex-1
+}
@@ -0,0 +1,135 @@
+package scenarigo
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/context"
+)
+
+func TestRunner_shardFiles(t *testing.T) {
+	names := []string{"e.yaml", "d.yaml", "c.yaml", "b.yaml", "a.yaml"}
+	tests := map[string]struct {
+		opts   []func(*Runner) error
+		expect []string
+	}{
+		"no shard": {
+			expect: names,
+		},
+		"first shard": {
+			opts:   []func(*Runner) error{WithShard(1, 2)},
+			expect: []string{"e.yaml", "c.yaml", "a.yaml"},
+		},
+		"second shard": {
+			opts:   []func(*Runner) error{WithShard(2, 2)},
+			expect: []string{"d.yaml", "b.yaml"},
+		},
+		"balanced first shard": {
+			opts:   []func(*Runner) error{WithShard(1, 2), WithShardReport("testdata/report/durations.json")},
+			expect: []string{"c.yaml", "b.yaml", "a.yaml"},
+		},
+		"balanced second shard": {
+			opts:   []func(*Runner) error{WithShard(2, 2), WithShardReport("testdata/report/durations.json")},
+			expect: []string{"e.yaml", "d.yaml"},
+		},
+		"more shards than files": {
+			opts:   []func(*Runner) error{WithShard(8, 8)},
+			expect: []string{},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			r, err := NewRunner(test.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			files := make([]string, len(names))
+			for i, name := range names {
+				files[i] = filepath.Join(r.rootDir, name)
+			}
+			var got []string
+			for _, f := range r.shardFiles(files) {
+				got = append(got, filepath.Base(f))
+			}
+			if got == nil {
+				got = []string{}
+			}
+			if diff := cmp.Diff(test.expect, got); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRunner_shardReaders(t *testing.T) {
+	tests := map[string]struct {
+		opts   []func(*Runner) error
+		expect []string
+	}{
+		"no shard": {
+			expect: []string{"0", "1", "2"},
+		},
+		"first shard": {
+			opts:   []func(*Runner) error{WithShard(1, 2)},
+			expect: []string{"0", "2"},
+		},
+		"second shard": {
+			opts:   []func(*Runner) error{WithShard(2, 2)},
+			expect: []string{"1"},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			readers := make([]io.Reader, 3)
+			for i := range readers {
+				readers[i] = strings.NewReader(fmt.Sprintf("title: scenario %d\nsteps: []\n", i))
+			}
+			r, err := NewRunner(append(test.opts, WithScenariosFromReader(readers...))...)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			var got []string
+			for _, f := range r.loadScenarioFiles(context.FromT(t)) {
+				got = append(got, f.name)
+			}
+			if diff := cmp.Diff(test.expect, got); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithShard(t *testing.T) {
+	tests := map[string]struct {
+		index int
+		total int
+	}{
+		"zero index": {
+			index: 0,
+			total: 2,
+		},
+		"index exceeds total": {
+			index: 3,
+			total: 2,
+		},
+		"zero total": {
+			index: 1,
+			total: 0,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewRunner(WithShard(test.index, test.total)); err == nil {
+				t.Fatal("expected error but no error")
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package scenarigo
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// WithShuffle returns a option which randomizes the order of the scenario files and the scenarios in each file.
+// The same seed reproduces the same order, so print the seed to reproduce the failures which depend on the order.
+// The steps in a scenario always run in the written order.
+func WithShuffle(seed int64) func(*Runner) error {
+	return func(r *Runner) error {
+		r.shuffle = true
+		r.shuffleSeed = seed
+		return nil
+	}
+}
+
+// WithCount returns a option which runs each scenario n times.
+// The pass rates of the scenarios are aggregated in the test report.
+func WithCount(n int) func(*Runner) error {
+	return func(r *Runner) error {
+		if n < 1 {
+			return fmt.Errorf("invalid count %d: must be greater than 0", n)
+		}
+		r.count = n
+		return nil
+	}
+}
+
+// arrangeScenarios repeats and shuffles the scenarios of files by the count and shuffle options.
+func (r *Runner) arrangeScenarios(files []*scenarioFile) {
+	if r.count > 1 {
+		for _, f := range files {
+			scns := f.scenarios
+			for i := 1; i < r.count; i++ {
+				f.scenarios = append(f.scenarios, scns...)
+			}
+		}
+	}
+	if !r.shuffle {
+		return
+	}
+	rnd := rand.New(rand.NewSource(r.shuffleSeed)) // nolint:gosec
+	rnd.Shuffle(len(files), func(i, j int) {
+		files[i], files[j] = files[j], files[i]
+	})
+	for _, f := range files {
+		scns := f.scenarios
+		rnd.Shuffle(len(scns), func(i, j int) {
+			scns[i], scns[j] = scns[j], scns[i]
+		})
+	}
+}
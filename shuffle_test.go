@@ -0,0 +1,82 @@
+package scenarigo
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+func TestRunner_arrangeScenarios(t *testing.T) {
+	newFiles := func() []*scenarioFile {
+		files := []*scenarioFile{}
+		for _, name := range []string{"a.yaml", "b.yaml", "c.yaml", "d.yaml"} {
+			f := &scenarioFile{name: name}
+			for _, title := range []string{"1", "2", "3", "4"} {
+				f.scenarios = append(f.scenarios, &schema.Scenario{Title: name + ":" + title})
+			}
+			files = append(files, f)
+		}
+		return files
+	}
+	arrange := func(t *testing.T, opts ...func(*Runner) error) []string {
+		t.Helper()
+		r, err := NewRunner(opts...)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		files := newFiles()
+		r.arrangeScenarios(files)
+		var got []string
+		for _, f := range files {
+			for _, scn := range f.scenarios {
+				got = append(got, scn.Title)
+			}
+		}
+		return got
+	}
+	var ordered []string
+	for _, f := range newFiles() {
+		for _, scn := range f.scenarios {
+			ordered = append(ordered, scn.Title)
+		}
+	}
+
+	t.Run("default", func(t *testing.T) {
+		if diff := cmp.Diff(ordered, arrange(t)); diff != "" {
+			t.Errorf("differs (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("shuffle", func(t *testing.T) {
+		got := arrange(t, WithShuffle(1))
+		if diff := cmp.Diff(got, arrange(t, WithShuffle(1))); diff != "" {
+			t.Errorf("the same seed must reproduce the same order (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff(ordered, got); diff == "" {
+			t.Error("not shuffled")
+		}
+		sorted := append([]string{}, got...)
+		sort.Strings(sorted)
+		if diff := cmp.Diff(ordered, sorted); diff != "" {
+			t.Errorf("scenarios differ (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("count", func(t *testing.T) {
+		got := arrange(t, WithCount(2))
+		expect := []string{}
+		for i := 0; i < len(ordered); i += 4 {
+			expect = append(expect, ordered[i:i+4]...)
+			expect = append(expect, ordered[i:i+4]...)
+		}
+		if diff := cmp.Diff(expect, got); diff != "" {
+			t.Errorf("differs (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("invalid count", func(t *testing.T) {
+		if _, err := NewRunner(WithCount(0)); err == nil {
+			t.Fatal("no error")
+		}
+	})
+}
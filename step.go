@@ -3,6 +3,7 @@ package scenarigo
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/lestrrat-go/backoff"
@@ -18,6 +19,7 @@ import (
 
 func runStep(ctx *context.Context, scenario *schema.Scenario, s *schema.Step, stepIdx int) *context.Context {
 	if s.Vars != nil {
+		checkReadOnlyVars(ctx, fmt.Sprintf("steps[%d].vars", stepIdx), s.Vars)
 		vars, err := ctx.ExecuteTemplate(s.Vars)
 		if err != nil {
 			ctx.Reporter().Fatal(
@@ -35,30 +37,22 @@ func runStep(ctx *context.Context, scenario *schema.Scenario, s *schema.Step, st
 		ctx = ctx.WithVars(vars)
 	}
 
+	if err := s.ValidateWait(); err != nil {
+		ctx.Reporter().Fatal(
+			errors.WithNodeAndColored(
+				errors.WithPath(err, fmt.Sprintf("steps[%d]", stepIdx)),
+				ctx.Node(),
+				ctx.EnabledColor(),
+			),
+		)
+	}
 	if s.Include != "" {
-		baseDir := filepath.Dir(scenario.Filepath())
-		include := filepath.Join(baseDir, s.Include)
-		scenarios, err := schema.LoadScenarios(include)
-		if err != nil {
-			ctx.Reporter().Fatalf(`failed to include "%s" as step: %s`, s.Include, err)
-		}
-		if len(scenarios) != 1 {
-			ctx.Reporter().Fatalf(`failed to include "%s" as step: must be a scenario`, s.Include)
-		}
-		testName, err := filepath.Rel(baseDir, include)
-		if err != nil {
-			ctx.Reporter().Fatalf(`failed to include "%s" as step: %s`, s.Include, err)
-		}
-		currentNode := ctx.Node()
-		ctx.Reporter().Run(testName, func(rptr reporter.Reporter) {
-			ctx = RunScenario(ctx.WithReporter(rptr).WithNode(scenarios[0].Node), scenarios[0])
-		})
-
-		// back node to current node
-		ctx = ctx.WithNode(currentNode)
-		return ctx
+		return include(ctx, scenario, s, stepIdx)
 	}
 	if s.Ref != "" {
+		if ctx.DryRun() {
+			ctx.Reporter().Skip("plugin steps don't run in the dry-run mode")
+		}
 		x, err := ctx.ExecuteTemplate(s.Ref)
 		if err != nil {
 			ctx.Reporter().Fatal(
@@ -92,10 +86,231 @@ func runStep(ctx *context.Context, scenario *schema.Scenario, s *schema.Step, st
 		return ctx
 	}
 
-	return invokeAndAssert(ctx, s, stepIdx)
+	if s.Wait != "" {
+		return wait(ctx, s, stepIdx)
+	}
+	if s.WaitFor != nil {
+		return waitFor(ctx, s, stepIdx)
+	}
+
+	return invokeAndAssert(ctx, s, stepIdx, scenario.Soft)
+}
+
+// wait sleeps for the duration of the step.
+func wait(ctx *context.Context, s *schema.Step, stepIdx int) *context.Context {
+	x, err := ctx.ExecuteTemplate(s.Wait)
+	if err != nil {
+		ctx.Reporter().Fatal(
+			errors.WithNodeAndColored(
+				errors.WrapPath(err, fmt.Sprintf("steps[%d].wait", stepIdx), "invalid wait"),
+				ctx.Node(),
+				ctx.EnabledColor(),
+			),
+		)
+	}
+	d, err := time.ParseDuration(fmt.Sprint(x))
+	if err != nil {
+		ctx.Reporter().Fatal(
+			errors.WithNodeAndColored(
+				errors.WrapPath(err, fmt.Sprintf("steps[%d].wait", stepIdx), "invalid wait"),
+				ctx.Node(),
+				ctx.EnabledColor(),
+			),
+		)
+	}
+	ctx.Reporter().Logf("wait for %s", d)
+	if ctx.DryRun() {
+		return ctx
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.RequestContext().Done():
+		ctx.Reporter().Fatal(ctx.RequestContext().Err())
+	}
+	return ctx
 }
 
-func invokeAndAssert(ctx *context.Context, s *schema.Step, stepIdx int) *context.Context {
+// waitFor polls the condition and the request of the step until they are satisfied.
+func waitFor(ctx *context.Context, s *schema.Step, stepIdx int) *context.Context {
+	if s.WaitFor.Condition == nil && s.Request.Invoker == nil {
+		ctx.Reporter().Fatal(
+			errors.WithNodeAndColored(
+				errors.ErrorPathf(fmt.Sprintf("steps[%d].waitFor", stepIdx), "condition or request must be specified"),
+				ctx.Node(),
+				ctx.EnabledColor(),
+			),
+		)
+	}
+	if ctx.DryRun() {
+		if s.WaitFor.Condition != nil {
+			ctx.Reporter().Log("the condition is not checked in the dry-run mode")
+		}
+		return dryRunRequest(ctx, s, stepIdx)
+	}
+	policy := s.WaitFor.RetryPolicy()
+	p, err := policy.Build()
+	if err != nil {
+		ctx.Reporter().Fatal(xerrors.Errorf("invalid waitFor policy: %w", err))
+	}
+
+	b, cancel := p.Start(ctx.RequestContext())
+	defer cancel()
+
+	var (
+		i         int
+		totalWait time.Duration
+	)
+	defer func() {
+		ctx.Reporter().RecordRetryStats(i, totalWait)
+	}()
+	for waitTime := time.Now(); backoff.Continue(b); waitTime = time.Now() {
+		if i > 0 {
+			totalWait += time.Since(waitTime)
+		}
+		ctx.Reporter().Logf("[%d] check the condition", i)
+		i++
+
+		newCtx := ctx
+		if s.Request.Invoker != nil {
+			var (
+				resp interface{}
+				err  error
+			)
+			newCtx, resp, err = s.Request.Invoke(ctx)
+			if err == nil {
+				var assertion assert.Assertion
+				assertion, err = s.Expect.Build(newCtx)
+				if err == nil {
+					err = assertion.Assert(resp)
+				}
+			}
+			if err != nil {
+				ctx.Reporter().Logf("not ready: %s", err)
+				continue
+			}
+		}
+		if ok, err := satisfied(newCtx, policy); !ok {
+			if err != nil {
+				ctx.Reporter().Logf("not ready: %s", err)
+			} else {
+				ctx.Reporter().Log("not ready: the condition is not satisfied")
+			}
+			continue
+		}
+		return newCtx
+	}
+
+	ctx.Reporter().Fatal(
+		errors.WithNodeAndColored(
+			errors.ErrorPathf(fmt.Sprintf("steps[%d].waitFor", stepIdx), "gave up waiting after %d attempts", i),
+			ctx.Node(),
+			ctx.EnabledColor(),
+		),
+	)
+	return ctx
+}
+
+// include runs the included scenario as a sub test of the step.
+// If the step has inputs by "with", the included scenario runs with only the inputs and the global variables as vars
+// and the step gets only the outputs of the included scenario.
+func include(ctx *context.Context, scenario *schema.Scenario, s *schema.Step, stepIdx int) *context.Context {
+	baseDir := filepath.Dir(scenario.Filepath())
+	include := scenario.IncludePath(s.Include)
+
+	stack := ctx.IncludeStack()
+	if len(stack) == 0 {
+		stack = []string{filepath.Clean(scenario.Filepath())}
+	}
+	for i, path := range stack {
+		if path == include {
+			ctx.Reporter().Fatal(
+				errors.WithNodeAndColored(
+					errors.ErrorPathf(
+						fmt.Sprintf("steps[%d].include", stepIdx),
+						"include cycle detected: %s", strings.Join(append(stack[i:], include), " -> "),
+					),
+					ctx.Node(),
+					ctx.EnabledColor(),
+				),
+			)
+		}
+	}
+
+	scenarios, err := schema.LoadScenarios(include, schema.WithStepLibrary(scenario.StepLibrary()))
+	if err != nil {
+		ctx.Reporter().Fatalf(`failed to include "%s" as step: %s`, s.Include, err)
+	}
+	if len(scenarios) != 1 {
+		ctx.Reporter().Fatalf(`failed to include "%s" as step: must be a scenario`, s.Include)
+	}
+	if filepath.IsAbs(include) {
+		// the include of the library step is rewritten to the absolute path
+		if abs, err := filepath.Abs(baseDir); err == nil {
+			baseDir = abs
+		}
+	}
+	testName, err := filepath.Rel(baseDir, include)
+	if err != nil {
+		ctx.Reporter().Fatalf(`failed to include "%s" as step: %s`, s.Include, err)
+	}
+
+	includeCtx := ctx.WithIncludeStack(append(stack[:len(stack):len(stack)], include))
+	if s.With != nil {
+		inputs, err := ctx.ExecuteTemplate(s.With)
+		if err != nil {
+			ctx.Reporter().Fatal(
+				errors.WithNodeAndColored(
+					errors.WrapPath(
+						err,
+						fmt.Sprintf("steps[%d].with", stepIdx),
+						"invalid inputs",
+					),
+					ctx.Node(),
+					ctx.EnabledColor(),
+				),
+			)
+		}
+		includeCtx = includeCtx.WithoutScenarioVars().WithVars(inputs)
+	}
+
+	currentNode := ctx.Node()
+	currentStack := ctx.IncludeStack()
+	var outputs interface{}
+	ctx.Reporter().Run(testName, func(rptr reporter.Reporter) {
+		scnCtx := RunScenario(includeCtx.WithReporter(rptr).WithNode(scenarios[0].Node), scenarios[0])
+		if scenarios[0].Outputs != nil {
+			outputs, err = scnCtx.ExecuteTemplate(scenarios[0].Outputs)
+			if err != nil && ctx.DryRun() {
+				// the outputs may refer to the responses which don't exist in the dry-run mode
+				rptr.Logf("failed to evaluate outputs: %s", err)
+			} else if err != nil {
+				rptr.Fatal(
+					errors.WithNodeAndColored(
+						errors.WrapPath(err, "outputs", "invalid outputs"),
+						scenarios[0].Node,
+						ctx.EnabledColor(),
+					),
+				)
+			}
+		}
+		if s.With == nil {
+			ctx = scnCtx
+		}
+	})
+
+	// back node and include stack to current ones
+	return ctx.WithNode(currentNode).WithIncludeStack(currentStack).WithOutputs(outputs)
+}
+
+// invokeAndAssert sends the request and asserts the response.
+// If soft is true, the assertion failure is recorded without stopping the step
+// to enable the following steps to use the response.
+func invokeAndAssert(ctx *context.Context, s *schema.Step, stepIdx int, soft bool) *context.Context {
+	if ctx.DryRun() {
+		return dryRunRequest(ctx, s, stepIdx)
+	}
 	policy, err := s.Retry.Build()
 	if err != nil {
 		ctx.Reporter().Fatal(xerrors.Errorf("invalid retry policy: %w", err))
@@ -104,8 +319,21 @@ func invokeAndAssert(ctx *context.Context, s *schema.Step, stepIdx int) *context
 	b, cancel := policy.Start(ctx.RequestContext())
 	defer cancel()
 
-	var i int
-	for backoff.Continue(b) {
+	var (
+		i           int
+		totalWait   time.Duration
+		assertedCtx *context.Context
+	)
+	if s.Retry != nil {
+		defer func() {
+			ctx.Reporter().RecordRetryStats(i, totalWait)
+		}()
+	}
+	for waitTime := time.Now(); backoff.Continue(b); waitTime = time.Now() {
+		if i > 0 {
+			totalWait += time.Since(waitTime)
+		}
+		assertedCtx = nil
 		ctx.Reporter().Logf("[%d] send request", i)
 		i++
 
@@ -121,6 +349,23 @@ func invokeAndAssert(ctx *context.Context, s *schema.Step, stepIdx int) *context
 					ctx.EnabledColor(),
 				),
 			)
+			if !shouldRetry(s.Retry, schema.RetryOnRequestError, s.Protocol, resp) {
+				break
+			}
+			continue
+		}
+		if ok, err := satisfied(newCtx, s.Retry); !ok {
+			if err != nil {
+				ctx.Reporter().Log(
+					errors.WithNodeAndColored(
+						errors.WrapPath(err, fmt.Sprintf("steps[%d].retry.until", stepIdx), "retry condition is not satisfied"),
+						ctx.Node(),
+						ctx.EnabledColor(),
+					),
+				)
+			} else {
+				ctx.Reporter().Log("retry condition is not satisfied")
+			}
 			continue
 		}
 		assertion, err := s.Expect.Build(newCtx)
@@ -132,9 +377,13 @@ func invokeAndAssert(ctx *context.Context, s *schema.Step, stepIdx int) *context
 					ctx.EnabledColor(),
 				),
 			)
+			if !shouldRetry(s.Retry, schema.RetryOnAssertionError, s.Protocol, resp) {
+				break
+			}
 			continue
 		}
 		if err := assertion.Assert(resp); err != nil {
+			assertedCtx = newCtx
 			err = errors.WithNodeAndColored(
 				errors.WithPath(err, fmt.Sprintf("steps[%d].expect", stepIdx)),
 				ctx.Node(),
@@ -147,11 +396,18 @@ func invokeAndAssert(ctx *context.Context, s *schema.Step, stepIdx int) *context
 			} else {
 				ctx.Reporter().Log(err)
 			}
+			if !shouldRetry(s.Retry, schema.RetryOnAssertionError, s.Protocol, resp) {
+				break
+			}
 			continue
 		}
 		return newCtx
 	}
 
+	if soft && assertedCtx != nil {
+		ctx.Reporter().Fail()
+		return assertedCtx
+	}
 	ctx.Reporter().FailNow()
 	return ctx
 }
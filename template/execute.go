@@ -14,6 +14,7 @@ import (
 var (
 	yamlMapItemType  = reflect.TypeOf(yaml.MapItem{})
 	yamlMapSliceType = reflect.TypeOf(yaml.MapSlice{})
+	typeError        = reflect.TypeOf((*error)(nil)).Elem()
 )
 
 // Execute executes templates of i with data.
@@ -0,0 +1,56 @@
+package template
+
+import (
+	"github.com/zoncoen/scenarigo/template/ast"
+	"github.com/zoncoen/scenarigo/template/token"
+)
+
+// Refs returns the keys of the variable name referred by the template without executing it.
+// For example, Refs("vars") returns ["user"] for the template "{{vars.user.name}}" and `{{vars["user"].name}}`.
+func (t *Template) Refs(name string) []string {
+	var keys []string
+	seen := map[string]struct{}{}
+	walkRefs(t.expr, name, func(key string) {
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+func walkRefs(expr ast.Expr, name string, f func(string)) {
+	switch e := expr.(type) {
+	case *ast.ParameterExpr:
+		if e.X != nil {
+			walkRefs(e.X, name, f)
+		}
+	case *ast.BinaryExpr:
+		walkRefs(e.X, name, f)
+		walkRefs(e.Y, name, f)
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok && x.Name == name {
+			f(e.Sel.Name)
+			return
+		}
+		walkRefs(e.X, name, f)
+	case *ast.IndexExpr:
+		if x, ok := e.X.(*ast.Ident); ok && x.Name == name {
+			if lit, ok := e.Index.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				f(lit.Value)
+				return
+			}
+		}
+		walkRefs(e.X, name, f)
+		walkRefs(e.Index, name, f)
+	case *ast.CallExpr:
+		walkRefs(e.Fun, name, f)
+		for _, arg := range e.Args {
+			walkRefs(arg, name, f)
+		}
+	case *ast.LeftArrowExpr:
+		walkRefs(e.Fun, name, f)
+		walkRefs(e.Arg, name, f)
+	}
+}
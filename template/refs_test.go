@@ -0,0 +1,65 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTemplate_Refs(t *testing.T) {
+	tests := map[string]struct {
+		str    string
+		expect []string
+	}{
+		"no parameter": {
+			str: "test",
+		},
+		"selector": {
+			str:    "{{vars.user.name}}",
+			expect: []string{"user"},
+		},
+		"other name": {
+			str: "{{ctx.vars.user}}",
+		},
+		"without key": {
+			str: "{{vars}}",
+		},
+		"index": {
+			str:    "{{vars.users[0]}}",
+			expect: []string{"users"},
+		},
+		"index by key": {
+			str:    `{{vars["user"].name}}`,
+			expect: []string{"user"},
+		},
+		"index by variable": {
+			str:    `{{vars[vars.key]}}`,
+			expect: []string{"key"},
+		},
+		"binary expr": {
+			str:    "{{vars.scheme}}://{{vars.host}}/{{vars.scheme}}",
+			expect: []string{"scheme", "host"},
+		},
+		"function call": {
+			str:    "{{plugins.test.Join(vars.a, vars.b)}}",
+			expect: []string{"a", "b"},
+		},
+		"left arrow function": {
+			str: `{{plugins.test.Fn <-}}:
+  arg: '{{vars.arg}}'`,
+			expect: []string{"arg"},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := New(test.str)
+			if err != nil {
+				t.Fatalf("failed to parse: %s", err)
+			}
+			if diff := cmp.Diff(test.expect, tmpl.Refs("vars")); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
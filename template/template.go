@@ -266,6 +266,13 @@ func (t *Template) executeFuncCall(call *ast.CallExpr, data interface{}) (interf
 	}
 
 	vs := funv.Call(args)
+	// the function can return an error as the second value
+	if len(vs) == 2 && funcType.Out(1) == typeError {
+		if err, _ := vs[1].Interface().(error); err != nil {
+			return nil, err
+		}
+		vs = vs[:1]
+	}
 	if len(vs) != 1 || !vs[0].IsValid() {
 		return nil, errors.Errorf("function should return a value")
 	}
@@ -112,6 +112,20 @@ func TestTemplate_Execute(t *testing.T) {
 			expect: 15,
 		},
 
+		"call function that returns an error": {
+			str: `{{f("ok")}}`,
+			data: map[string]func(string) (string, error){
+				"f": func(s string) (string, error) { return s, nil },
+			},
+			expect: "ok",
+		},
+		"function returns an error": {
+			str: `{{f("ok")}}`,
+			data: map[string]func(string) (string, error){
+				"f": func(s string) (string, error) { return "", errors.New("failed") },
+			},
+			expectError: true,
+		},
 		"invalid function argument": {
 			str: `{{f(1, 2, 3)}}`,
 			data: map[string]func(int, int) int{
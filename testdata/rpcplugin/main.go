@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zoncoen/scenarigo/plugin/rpcplugin"
+)
+
+func main() {
+	rpcplugin.Serve(map[string]interface{}{
+		"Greet": func(name string) string {
+			return fmt.Sprintf("Hello, %s!", name)
+		},
+		"Login": rpcplugin.StepFunc(func(req *rpcplugin.StepRequest) (*rpcplugin.StepResponse, error) {
+			return &rpcplugin.StepResponse{
+				Response: map[string]interface{}{
+					"token": "xxx",
+				},
+			}, nil
+		}),
+		"Check": rpcplugin.StepFunc(func(req *rpcplugin.StepRequest) (*rpcplugin.StepResponse, error) {
+			vars, ok := req.Vars.(map[string]interface{})
+			if !ok {
+				return nil, errors.New("vars not found")
+			}
+			if got, expect := vars["token"], "xxx"; got != expect {
+				return nil, fmt.Errorf("expected token %q but got %q", expect, got)
+			}
+			if got, expect := vars["greeting"], "Hello, scenarigo!"; got != expect {
+				return nil, fmt.Errorf("expected greeting %q but got %q", expect, got)
+			}
+			return &rpcplugin.StepResponse{
+				Logs: []string{"checked"},
+			}, nil
+		}),
+	})
+}
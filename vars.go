@@ -0,0 +1,86 @@
+package scenarigo
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/zoncoen/scenarigo/internal/dotenv"
+)
+
+// WithVars returns a option which sets global variables.
+// The variables can be referred from all scenarios and override the variables set before.
+func WithVars(vars map[string]interface{}) func(*Runner) error {
+	return func(r *Runner) error {
+		r.vars = mergeVars(r.vars, vars)
+		return nil
+	}
+}
+
+// WithProfile returns a option which overlays the variables of the profile defined in the configuration.
+// It must be set after WithConfig.
+func WithProfile(name string) func(*Runner) error {
+	return func(r *Runner) error {
+		if name == "" {
+			return nil
+		}
+		p, ok := r.profiles[name]
+		if !ok {
+			return fmt.Errorf("unknown profile %q", name)
+		}
+		r.vars = mergeVars(r.vars, p.Vars)
+		return nil
+	}
+}
+
+// WithVarsFile returns a option which loads global variables from a YAML or JSON file.
+func WithVarsFile(path string) func(*Runner) error {
+	return func(r *Runner) error {
+		r.varsFiles = append(r.varsFiles, path)
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read vars file: %w", err)
+		}
+		var vars map[string]interface{}
+		if err := yaml.Unmarshal(b, &vars); err != nil {
+			return fmt.Errorf("failed to load vars file %s: %w", path, err)
+		}
+		r.vars = mergeVars(r.vars, vars)
+		return nil
+	}
+}
+
+// WithEnvFile returns a option which loads environment variables from a .env file.
+// The variables can be referred by "env" in templates, but the environment variables of the process take precedence.
+func WithEnvFile(path string) func(*Runner) error {
+	return func(r *Runner) error {
+		r.envFiles = append(r.envFiles, path)
+		env, err := dotenv.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to load env file %s: %w", path, err)
+		}
+		if r.env == nil {
+			r.env = map[string]string{}
+		}
+		for k, v := range env {
+			r.env[k] = v
+		}
+		return nil
+	}
+}
+
+// mergeVars returns the variables of base overridden by v.
+func mergeVars(base, v map[string]interface{}) map[string]interface{} {
+	if len(v) == 0 {
+		return base
+	}
+	merged := make(map[string]interface{}, len(base)+len(v))
+	for k, val := range base {
+		merged[k] = val
+	}
+	for k, val := range v {
+		merged[k] = val
+	}
+	return merged
+}
@@ -0,0 +1,148 @@
+package scenarigo
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/reporter"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+func TestRunner_Vars(t *testing.T) {
+	config := &schema.Config{
+		Vars: map[string]interface{}{
+			"url":    "http://localhost",
+			"tenant": "config",
+		},
+		Profiles: map[string]schema.ProfileConfig{
+			"staging": {
+				Vars: map[string]interface{}{
+					"url": "https://staging.example.com",
+				},
+			},
+		},
+	}
+	tests := map[string]struct {
+		opts   []func(*Runner) error
+		expect map[string]interface{}
+	}{
+		"config": {
+			opts: []func(*Runner) error{WithConfig(config)},
+			expect: map[string]interface{}{
+				"url":    "http://localhost",
+				"tenant": "config",
+			},
+		},
+		"profile": {
+			opts: []func(*Runner) error{WithConfig(config), WithProfile("staging")},
+			expect: map[string]interface{}{
+				"url":    "https://staging.example.com",
+				"tenant": "config",
+			},
+		},
+		"vars file": {
+			opts: []func(*Runner) error{
+				WithConfig(config),
+				WithVarsFile(filepath.Join("testdata", "vars", "vars.yaml")),
+			},
+			expect: map[string]interface{}{
+				"url":    "http://localhost",
+				"tenant": "file",
+				"region": "asia",
+			},
+		},
+		"vars": {
+			opts: []func(*Runner) error{
+				WithConfig(config),
+				WithProfile("staging"),
+				WithVarsFile(filepath.Join("testdata", "vars", "vars.yaml")),
+				WithVars(map[string]interface{}{"tenant": "flag"}),
+			},
+			expect: map[string]interface{}{
+				"url":    "https://staging.example.com",
+				"tenant": "flag",
+				"region": "asia",
+			},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			r, err := NewRunner(test.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(test.expect, r.vars); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		})
+	}
+	t.Run("unknown profile", func(t *testing.T) {
+		if _, err := NewRunner(WithConfig(config), WithProfile("production")); err == nil {
+			t.Fatal("expected error but no error")
+		}
+	})
+	t.Run("vars file not found", func(t *testing.T) {
+		if _, err := NewRunner(WithVarsFile("not-found.yaml")); err == nil {
+			t.Fatal("expected error but no error")
+		}
+	})
+}
+
+func TestRunner_Run_Vars(t *testing.T) {
+	r, err := NewRunner(
+		WithScenariosFromReader(strings.NewReader(`
+title: global vars
+steps:
+- waitFor:
+    condition: "{{vars.ready}}"
+    constant:
+      interval: 1ms
+      maxRetries: 0
+`)),
+		WithVars(map[string]interface{}{"ready": true}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var b bytes.Buffer
+	ok := reporter.Run(func(rptr reporter.Reporter) {
+		r.Run(context.New(rptr))
+	}, reporter.WithWriter(&b))
+	if !ok {
+		t.Fatalf("scenario failed:\n%s", b.String())
+	}
+}
+
+func TestRunner_EnvFile(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		r, err := NewRunner(
+			WithScenariosFromReader(strings.NewReader(`
+title: env file
+steps:
+- wait: "{{env.SCENARIGO_TEST_WAIT}}"
+`)),
+			WithEnvFile(filepath.Join("testdata", "vars", "test.env")),
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		var b bytes.Buffer
+		ok := reporter.Run(func(rptr reporter.Reporter) {
+			r.Run(context.New(rptr))
+		}, reporter.WithWriter(&b))
+		if !ok {
+			t.Fatalf("scenario failed:\n%s", b.String())
+		}
+	})
+	t.Run("not found", func(t *testing.T) {
+		if _, err := NewRunner(WithEnvFile("not-found.env")); err == nil {
+			t.Fatal("expected error but no error")
+		}
+	})
+}
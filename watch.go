@@ -0,0 +1,367 @@
+package scenarigo
+
+import (
+	gocontext "context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+// WithKeepVars returns a option which keeps the variables bound by the steps
+// and makes them available in the following runs as global variables.
+// It is intended to be used with Watch to rerun scenarios which depend on other scenarios.
+func WithKeepVars(keep bool) func(*Runner) error {
+	return func(r *Runner) error {
+		if keep && r.keptVars == nil {
+			r.keptVars = &keptVars{vars: map[string]interface{}{}}
+		}
+		if !keep {
+			r.keptVars = nil
+		}
+		return nil
+	}
+}
+
+// keptVars holds the variables bound by the steps of the previous runs.
+type keptVars struct {
+	m    sync.Mutex
+	vars map[string]interface{}
+}
+
+// keep keeps the variables bound by the steps of s from the scenario context.
+func (v *keptVars) keep(ctx *context.Context, s *schema.Scenario) {
+	v.m.Lock()
+	defer v.m.Unlock()
+	for _, step := range s.Steps {
+		for name := range step.Bind.Vars {
+			if val, ok := ctx.Vars().ExtractByKey(name); ok {
+				v.vars[name] = val
+			}
+		}
+	}
+}
+
+// WithReload returns a option which sets the function to rebuild the runner
+// when the configuration file, the step libraries, or the vars and env files change in the watch mode.
+// By default, the runner is rebuilt by the same options, so the changes of the configuration file are not applied.
+func WithReload(reload func() (*Runner, error)) func(*Runner) error {
+	return func(r *Runner) error {
+		r.reload = reload
+		return nil
+	}
+}
+
+// rebuild returns a new runner which loads the configuration and the files again.
+// The kept variables are taken over.
+func (r *Runner) rebuild() (*Runner, error) {
+	var (
+		nr  *Runner
+		err error
+	)
+	if r.reload != nil {
+		nr, err = r.reload()
+	} else {
+		nr, err = NewRunner(r.opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if nr.keptVars != nil && r.keptVars != nil {
+		nr.keptVars = r.keptVars
+	}
+	return nr, nil
+}
+
+func (v *keptVars) get() map[string]interface{} {
+	v.m.Lock()
+	defer v.m.Unlock()
+	vars := make(map[string]interface{}, len(v.vars))
+	for k, val := range v.vars {
+		vars[k] = val
+	}
+	return vars
+}
+
+// Watch runs the scenarios by run, and then watches the files until ctx is done.
+// When the scenario files, the included files, or the files in the plugin directory change,
+// it calls run again with the runner which runs only the affected scenarios and the changed files.
+// When the configuration file, the step libraries, the vars and env files, or the plugins for all scenarios change,
+// the runner is rebuilt and all scenarios run again.
+// When the setup or teardown scenario files change, all scenarios run again.
+// The files are checked every interval because it polls the modification times of the files.
+// The errors on watching like the invalid configuration are passed to onError, and it keeps watching with the previous runner
+// until the files are fixed. Only the error on starting is returned.
+func (r *Runner) Watch(ctx gocontext.Context, interval time.Duration, run func(r *Runner, changed []string), onError func(err error)) error {
+	w := &watcher{
+		runner: r,
+		deps:   map[string][]string{},
+		mtimes: map[string]time.Time{},
+	}
+	files, err := w.scan()
+	if err != nil {
+		return err
+	}
+	w.poll(files)
+	run(w.runner.withScenarioFiles(files), nil)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		files, err := w.scan()
+		if err != nil {
+			onError(err)
+			continue
+		}
+		changed := w.poll(files)
+		if len(changed) == 0 {
+			continue
+		}
+		if w.isGlobal(changed) {
+			if w.reloads(changed) {
+				nr, err := w.runner.rebuild()
+				if err != nil {
+					onError(fmt.Errorf("failed to reload: %w", err))
+					continue
+				}
+				// keep the previous runner if the new one fails to find the scenarios
+				nw := &watcher{runner: nr, deps: map[string][]string{}}
+				nfiles, err := nw.scan()
+				if err != nil {
+					onError(fmt.Errorf("failed to reload: %w", err))
+					continue
+				}
+				w.runner = nr
+				w.deps = nw.deps
+				files = nfiles
+			}
+			// watch the files newly referred by the changes
+			w.poll(files)
+			run(w.runner.withScenarioFiles(files), changed)
+			continue
+		}
+		affected := w.affected(files, changed)
+		for _, f := range affected {
+			w.updateDeps(f)
+		}
+		// watch the files newly included by the changes
+		w.poll(files)
+		run(w.runner.withScenarioFiles(affected), changed)
+	}
+}
+
+// withScenarioFiles returns a copy of r which runs only files.
+// The files must be already sharded.
+func (r *Runner) withScenarioFiles(files []string) *Runner {
+	sub := *r
+	sub.scenarioFiles = files
+	sub.scenarioReaders = nil
+	sub.shardTotal = 0
+	return &sub
+}
+
+// watcher tracks the scenario files and their dependencies.
+type watcher struct {
+	runner *Runner
+	deps   map[string][]string
+	mtimes map[string]time.Time
+}
+
+// scan returns the scenario files to watch.
+// The files are searched again to find new files.
+func (w *watcher) scan() ([]string, error) {
+	files := w.runner.scenarioFiles
+	if len(w.runner.scenarioPaths) > 0 {
+		var err error
+		files, err = getAllFiles(w.runner.scenarioPaths...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	files = w.runner.shardFiles(w.runner.filterFiles(files))
+	for _, f := range files {
+		if _, ok := w.deps[f]; !ok {
+			w.updateDeps(f)
+		}
+	}
+	return files, nil
+}
+
+func (w *watcher) updateDeps(file string) {
+	var pluginDir string
+	if w.runner.pluginDir != nil {
+		pluginDir = *w.runner.pluginDir
+	}
+	scns, err := schema.LoadScenarios(file, schema.WithStepLibrary(w.runner.stepLibrary))
+	if err != nil {
+		w.deps[file] = nil
+		return
+	}
+	deps := map[string]struct{}{}
+	for _, scn := range scns {
+		for _, d := range scn.Dependencies(pluginDir) {
+			deps[d] = struct{}{}
+		}
+	}
+	paths := make([]string, 0, len(deps))
+	for d := range deps {
+		paths = append(paths, d)
+	}
+	sort.Strings(paths)
+	w.deps[file] = paths
+}
+
+// configFiles returns the files which are loaded on building the runner.
+func (w *watcher) configFiles() []string {
+	var files []string
+	if w.runner.configFile != "" {
+		files = append(files, w.runner.configFile)
+	}
+	if len(w.runner.stepLibraryPaths) > 0 {
+		libs, err := getAllFiles(w.runner.stepLibraryPaths...)
+		if err != nil {
+			libs = w.runner.stepLibraryPaths
+		}
+		files = append(files, libs...)
+	}
+	files = append(files, w.runner.varsFiles...)
+	return append(files, w.runner.envFiles...)
+}
+
+// hookFiles returns the setup and teardown scenario files.
+func (w *watcher) hookFiles() []string {
+	files := append([]string{}, w.runner.setupFiles...)
+	return append(files, w.runner.teardownFiles...)
+}
+
+// pluginFiles returns the files in the plugin directory and the plugins for all scenarios.
+func (w *watcher) pluginFiles() []string {
+	var files []string
+	if w.runner.pluginDir != nil {
+		_ = filepath.WalkDir(*w.runner.pluginDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if path != *w.runner.pluginDir && d.Name()[0] == '.' {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			files = append(files, path)
+			return nil
+		})
+	}
+	return append(files, w.globalPlugins()...)
+}
+
+func (w *watcher) globalPlugins() []string {
+	var pluginDir string
+	if w.runner.pluginDir != nil {
+		pluginDir = *w.runner.pluginDir
+	}
+	plugins := make([]string, 0, len(w.runner.plugins))
+	for _, p := range w.runner.plugins {
+		plugins = append(plugins, filepath.Join(pluginDir, p))
+	}
+	return plugins
+}
+
+// isGlobal reports whether the changes affect all scenarios.
+func (w *watcher) isGlobal(changed []string) bool {
+	return w.reloads(changed) || containsPath(w.hookFiles(), changed) || containsPath(w.globalPlugins(), changed)
+}
+
+// reloads reports whether the changes need to rebuild the runner.
+func (w *watcher) reloads(changed []string) bool {
+	return containsPath(w.configFiles(), changed)
+}
+
+// containsPath reports whether paths contain any of targets.
+func containsPath(paths, targets []string) bool {
+	set := make(map[string]struct{}, len(paths))
+	for _, p := range paths {
+		set[filepath.Clean(p)] = struct{}{}
+	}
+	for _, t := range targets {
+		if _, ok := set[filepath.Clean(t)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// poll updates the modification times of the files and their dependencies and returns the changed files.
+// The new scenario files and the new files in the plugin directory are also treated as changed.
+func (w *watcher) poll(files []string) []string {
+	targets := map[string]bool{}
+	for _, f := range w.configFiles() {
+		targets[f] = false
+	}
+	for _, f := range w.hookFiles() {
+		targets[f] = false
+	}
+	for _, f := range w.pluginFiles() {
+		targets[f] = true
+	}
+	for _, f := range files {
+		for _, d := range w.deps[f] {
+			targets[d] = false
+		}
+	}
+	for _, f := range files {
+		targets[f] = true
+	}
+	var changed []string
+	for path, notifyNew := range targets {
+		var mtime time.Time
+		if info, err := os.Stat(path); err == nil {
+			mtime = info.ModTime()
+		}
+		prev, ok := w.mtimes[path]
+		if (ok && !prev.Equal(mtime)) || (!ok && notifyNew) {
+			changed = append(changed, path)
+		}
+		w.mtimes[path] = mtime
+	}
+	for path := range w.mtimes {
+		if _, ok := targets[path]; !ok {
+			delete(w.mtimes, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// affected returns the scenario files which are changed or depend on the changed files.
+func (w *watcher) affected(files, changed []string) []string {
+	set := make(map[string]struct{}, len(changed))
+	for _, c := range changed {
+		set[filepath.Clean(c)] = struct{}{}
+	}
+	var affected []string
+	for _, f := range files {
+		if _, ok := set[f]; ok {
+			affected = append(affected, f)
+			continue
+		}
+		for _, d := range w.deps[f] {
+			if _, ok := set[filepath.Clean(d)]; ok {
+				affected = append(affected, f)
+				break
+			}
+		}
+	}
+	return affected
+}
@@ -0,0 +1,281 @@
+package scenarigo
+
+import (
+	gocontext "context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zoncoen/scenarigo/context"
+	"github.com/zoncoen/scenarigo/plugin"
+	"github.com/zoncoen/scenarigo/reporter"
+	"github.com/zoncoen/scenarigo/schema"
+)
+
+func TestRunner_Watch(t *testing.T) {
+	dir := t.TempDir()
+	write := func(t *testing.T, name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		// ensure the modification time changes
+		mtime := time.Now().Add(time.Hour)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(t, "a.yaml", "title: a\nsteps:\n- include: steps/included.yml\n")
+	write(t, "b.yaml", "title: b\nsteps:\n- title: b\n")
+	if err := os.Mkdir(filepath.Join(dir, "steps"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	write(t, filepath.Join("steps", "included.yml"), "title: included\nsteps:\n- title: included\n")
+
+	r, err := NewRunner(WithScenarios(dir), WithRunPattern("^[ac]$"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	type run struct {
+		files   []string
+		changed []string
+	}
+	runs := make(chan run)
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	done := make(chan error)
+	go func() {
+		done <- r.Watch(ctx, 10*time.Millisecond, func(r *Runner, changed []string) {
+			var got run
+			for _, f := range r.ScenarioFiles() {
+				rel, _ := filepath.Rel(dir, f)
+				got.files = append(got.files, rel)
+			}
+			for _, f := range changed {
+				rel, _ := filepath.Rel(dir, f)
+				got.changed = append(got.changed, rel)
+			}
+			runs <- got
+		}, func(err error) {
+			t.Errorf("unexpected error: %s", err)
+		})
+	}()
+	receive := func(t *testing.T, expect run) {
+		t.Helper()
+		select {
+		case got := <-runs:
+			if diff := cmp.Diff(expect, got, cmp.AllowUnexported(run{})); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+
+	receive(t, run{files: []string{"a.yaml"}})
+
+	write(t, filepath.Join("steps", "included.yml"), "title: included\nsteps:\n- title: changed\n")
+	receive(t, run{
+		files:   []string{"a.yaml"},
+		changed: []string{filepath.Join("steps", "included.yml")},
+	})
+
+	write(t, "c.yaml", "title: c\nsteps:\n- title: c\n")
+	receive(t, run{
+		files:   []string{"c.yaml"},
+		changed: []string{"c.yaml"},
+	})
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestRunner_Watch_Reload(t *testing.T) {
+	dir := t.TempDir()
+	write := func(t *testing.T, name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		// ensure the modification time changes
+		mtime := time.Now().Add(time.Hour)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(t, filepath.Join("scenarios", "a.yaml"), "title: a\nsteps:\n- uses: lib.step\n")
+	write(t, filepath.Join("scenarios", "b.yaml"), "title: b\nsteps:\n- title: b\n")
+	write(t, filepath.Join("steps", "lib.yaml"), "step:\n  title: before\n")
+	write(t, "vars.yaml", "name: before\n")
+	write(t, "setup.yaml", "title: setup\nsteps:\n- title: setup\n")
+	if err := os.Mkdir(filepath.Join(dir, "plugins"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewRunner(
+		WithScenarios(filepath.Join(dir, "scenarios")),
+		WithStepLibraries(filepath.Join(dir, "steps")),
+		WithVarsFile(filepath.Join(dir, "vars.yaml")),
+		WithPluginDir(filepath.Join(dir, "plugins")),
+		WithSetup(filepath.Join(dir, "setup.yaml")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type run struct {
+		files   []string
+		changed []string
+		step    string
+		name    interface{}
+	}
+	runs := make(chan run)
+	errs := make(chan error)
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	done := make(chan error)
+	go func() {
+		done <- r.Watch(ctx, 10*time.Millisecond, func(r *Runner, changed []string) {
+			got := run{
+				step: r.stepLibrary["lib.step"].Title,
+				name: r.vars["name"],
+			}
+			for _, f := range r.ScenarioFiles() {
+				rel, _ := filepath.Rel(dir, f)
+				got.files = append(got.files, rel)
+			}
+			for _, f := range changed {
+				rel, _ := filepath.Rel(dir, f)
+				got.changed = append(got.changed, rel)
+			}
+			runs <- got
+		}, func(err error) {
+			errs <- err
+		})
+	}()
+	receive := func(t *testing.T, expect run) {
+		t.Helper()
+		select {
+		case got := <-runs:
+			if diff := cmp.Diff(expect, got, cmp.AllowUnexported(run{})); diff != "" {
+				t.Errorf("differs (-want +got):\n%s", diff)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+
+	all := []string{filepath.Join("scenarios", "a.yaml"), filepath.Join("scenarios", "b.yaml")}
+	receive(t, run{files: all, step: "before", name: "before"})
+
+	write(t, filepath.Join("steps", "lib.yaml"), "step:\n  title: after\n")
+	receive(t, run{
+		files:   all,
+		changed: []string{filepath.Join("steps", "lib.yaml")},
+		step:    "after",
+		name:    "before",
+	})
+
+	write(t, "vars.yaml", "name: after\n")
+	receive(t, run{
+		files:   all,
+		changed: []string{"vars.yaml"},
+		step:    "after",
+		name:    "after",
+	})
+
+	write(t, "setup.yaml", "title: setup\nsteps:\n- title: changed\n")
+	receive(t, run{
+		files:   all,
+		changed: []string{"setup.yaml"},
+		step:    "after",
+		name:    "after",
+	})
+
+	// keep watching with the previous runner until the broken file is fixed
+	write(t, "vars.yaml", "- broken\n")
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "failed to reload") {
+			t.Errorf("unexpected error: %s", err)
+		}
+	case got := <-runs:
+		t.Fatalf("unexpected run: %v", got)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+	write(t, "vars.yaml", "name: fixed\n")
+	receive(t, run{
+		files:   all,
+		changed: []string{"vars.yaml"},
+		step:    "after",
+		name:    "fixed",
+	})
+
+	write(t, filepath.Join("plugins", "new.so"), "")
+	receive(t, run{
+		changed: []string{filepath.Join("plugins", "new.so")},
+		step:    "after",
+		name:    "fixed",
+	})
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestRunner_KeepVars(t *testing.T) {
+	runner, err := NewRunner(
+		WithScenariosFromReader(strings.NewReader(`
+title: login
+steps:
+- ref: '{{plugins.pass}}'
+  bind:
+    vars:
+      token: '{{"secret"}}'
+`)),
+		WithKeepVars(true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plugins := map[string]interface{}{
+		"pass": plugin.StepFunc(func(ctx *context.Context, step *schema.Step) *context.Context {
+			return ctx
+		}),
+		"check": plugin.StepFunc(func(ctx *context.Context, step *schema.Step) *context.Context {
+			if v, ok := ctx.Vars().ExtractByKey("token"); !ok || v != "secret" {
+				ctx.Reporter().Fatalf("unexpected token: %v", v)
+			}
+			return ctx
+		}),
+	}
+	run := func(r *Runner) bool {
+		return reporter.Run(func(rptr reporter.Reporter) {
+			r.Run(context.New(rptr).WithPlugins(plugins))
+		})
+	}
+	if !run(runner) {
+		t.Fatal("failed to run login")
+	}
+
+	next := runner.withScenarioFiles(nil)
+	next.scenarioReaders = []io.Reader{strings.NewReader(`
+title: use token
+steps:
+- ref: '{{plugins.check}}'
+`)}
+	if !run(next) {
+		t.Fatal("the bound variable is not kept")
+	}
+}